@@ -0,0 +1,56 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd || linux || netbsd || openbsd
+// +build darwin freebsd linux netbsd openbsd
+
+package main
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/core"
+	"os"
+)
+
+// analyze replays captures through the matching of a live
+// run; it needs no privileges.
+func analyze(options []string) {
+	arg, err := core.ParseAnalyzeArgs(options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Aborted: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%s", core.AnalyzeUsage)
+		os.Exit(2)
+	}
+	if arg.Help {
+		fmt.Fprintf(os.Stderr, "%s", core.AnalyzeUsage)
+		os.Exit(2)
+	}
+
+	analysis := core.NewAnalysis(arg.Window)
+	for _, name := range arg.Files {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Aborted: %v\n", err)
+			os.Exit(2)
+		}
+		packets, err := core.ReadCapture(f)
+		f.Close()
+		if err != nil && len(packets) == 0 {
+			fmt.Fprintf(os.Stderr, "Aborted: %v: %v\n", name, err)
+			os.Exit(2)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v: %v; analysing the first %d packets\n", name, err, len(packets))
+		}
+		for _, p := range packets {
+			analysis.Add(p)
+		}
+	}
+
+	if arg.Target != "" {
+		analysis.Target(arg.Target).Render(os.Stdout)
+		return
+	}
+	analysis.Render(os.Stdout)
+}
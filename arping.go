@@ -0,0 +1,88 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd || linux || netbsd || openbsd
+// +build darwin freebsd linux netbsd openbsd
+
+package main
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/core"
+	"os"
+	"sync"
+	"time"
+)
+
+// arping checks layer-2 reachability of an on-link target.
+// Replies from more than one MAC address hint at an IP conflict.
+func arping(arg *core.Arg) {
+	name := arg.Interface
+	if name == "0.0.0.0" {
+		name = ""
+	}
+	ifi, src, err := core.OnLinkInterface(name, arg.Target.IP)
+	if err != nil {
+		fatal(err)
+	}
+	p, err := core.NewARPPinger(ifi, src, arg.Target.IP)
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Fprintf(out, "ARPING %v from %v %v\n", arg.Target.IP, src, ifi.Name)
+	var lock sync.Mutex
+	responders := make(map[string]int)
+	setTrailer(func() {
+		lock.Lock()
+		defer lock.Unlock()
+		if len(responders) > 1 {
+			fmt.Fprintf(out, "%d MAC addresses answered for %v:\n", len(responders), arg.Target.IP)
+			for mac, n := range responders {
+				fmt.Fprintf(out, "\t%v %d replies\n", mac, n)
+			}
+		}
+	})
+	var first string
+	for i := 1; i <= int(arg.Count); i++ {
+		counter.OnSent()
+		events.Emit(core.Event{Type: core.EventSent, Target: arg.Target.IP.String(), Seq: i})
+		responses, err := p.Ping(pause * time.Second)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%d send: %v\n", i, err)
+			continue
+		}
+		if len(responses) == 0 {
+			if templates.Reply == nil {
+				fmt.Fprintf(out, "Timeout from %v: arp_seq=%v\n", arg.Target.IP, i)
+			}
+			report(core.Event{Type: core.EventTimeout, Target: arg.Target.IP.String(), Seq: i})
+			continue
+		}
+		counter.OnReception()
+		observe(responses[0].RTT)
+		for _, r := range responses {
+			mac := r.MAC.String()
+			if first == "" {
+				first = mac
+			}
+			lock.Lock()
+			responders[mac]++
+			lock.Unlock()
+			note := ""
+			if mac != first {
+				note = " DUPLICATE, possible IP conflict"
+			}
+			if templates.Reply == nil {
+				fmt.Fprintf(out, "Unicast reply from %v [%v]: arp_seq=%v time=%v%s\n", arg.Target.IP, mac, i, r.RTT, note)
+			}
+			report(core.Event{Type: core.EventReply, Target: arg.Target.IP.String(), Seq: i,
+				RTT: r.RTT.Nanoseconds(), Peer: arg.Target.IP.String(), MAC: mac})
+		}
+	}
+	p.Close()
+
+	summarize(arg.Target.IP.String())
+	exit(arg.Target.IP.String())
+}
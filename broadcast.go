@@ -0,0 +1,142 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd || linux || netbsd || openbsd
+// +build darwin freebsd linux netbsd openbsd
+
+package main
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/core"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// listenBroadcast opens an ICMP socket allowed to send to
+// broadcast addresses, with the multicast interface & TTL set.
+func listenBroadcast(arg *core.Arg, ifacetarget net.IP) (*net.IPConn, *ipv4.PacketConn, error) {
+	pc, err := net.ListenPacket("ip4:icmp", ifacetarget.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	c := pc.(*net.IPConn)
+	rc, err := c.SyscallConn()
+	if err != nil {
+		c.Close()
+		return nil, nil, err
+	}
+	var serr error
+	err = rc.Control(func(fd uintptr) {
+		serr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_BROADCAST, 1)
+	})
+	if err == nil {
+		err = serr
+	}
+	if err != nil {
+		c.Close()
+		return nil, nil, err
+	}
+
+	p := ipv4.NewPacketConn(c)
+	if arg.Interface != "0.0.0.0" {
+		if ifi, err := net.InterfaceByName(arg.Interface); err == nil {
+			if err := p.SetMulticastInterface(ifi); err != nil {
+				c.Close()
+				return nil, nil, err
+			}
+		}
+	}
+	if arg.TTL > 0 {
+		if err := p.SetMulticastTTL(arg.TTL); err != nil {
+			c.Close()
+			return nil, nil, err
+		}
+		if err := p.SetTTL(arg.TTL); err != nil {
+			c.Close()
+			return nil, nil, err
+		}
+	}
+	return c, p, nil
+}
+
+// broadcast pings a broadcast or multicast address and
+// keeps counters & RTT statistics for every host that answers.
+func broadcast(arg *core.Arg, ifacetarget net.IP) {
+	c, p, err := listenBroadcast(arg, ifacetarget)
+	if err != nil {
+		fatal(err)
+	}
+	responders := core.NewResponders()
+	setTrailer(func() { responders.Render(out) })
+	id := os.Getpid() & 0xffff
+	rb := make([]byte, 1500)
+
+	fmt.Fprintf(out, "PING %v %v(%v) bytes of data.\n", arg.Target.IP, payloadLen, payloadAndHeader)
+	for i := 1; i <= int(arg.Count); i++ {
+		wm := core.NewEcho(payload, i)
+		wb, err := wm.Marshal(nil)
+		if err != nil {
+			fatal(err)
+		}
+		start := time.Now()
+		if _, err := c.WriteTo(wb, arg.Target); err != nil {
+			fmt.Fprintf(os.Stderr, "%d connect: %v\n", i, err)
+			unsent++
+			time.Sleep(pause * time.Second)
+			continue
+		}
+		counter.OnSent()
+		events.Emit(core.Event{Type: core.EventSent, Target: arg.Target.IP.String(), Seq: i})
+
+		// every reply within the window counts
+		if err := p.SetReadDeadline(start.Add(pause * time.Second)); err != nil {
+			fatal(err)
+		}
+		answered := false
+		for {
+			n, _, peer, err := p.ReadFrom(rb)
+			if err != nil {
+				break
+			}
+			elapsed := time.Since(start)
+			rm, err := icmp.ParseMessage(1, rb[:n])
+			if err != nil || rm.Type != ipv4.ICMPTypeEchoReply {
+				continue
+			}
+			echo, ok := rm.Body.(*icmp.Echo)
+			if !ok || echo.ID != id || echo.Seq != i {
+				continue
+			}
+			responders.OnReply(peer.String(), nanoToMilli(elapsed))
+			note := ""
+			if answered {
+				note = " (DUP!)"
+			} else {
+				answered = true
+				counter.OnReception()
+				observe(elapsed)
+			}
+			if templates.Reply == nil {
+				fmt.Fprintf(out, "%v bytes from %v: icmp_seq=%v time=%v%s\n", n, peer, i, elapsed, note)
+			}
+			report(core.Event{Type: core.EventReply, Target: arg.Target.IP.String(), Seq: i,
+				RTT: elapsed.Nanoseconds(), Peer: peer.String()})
+		}
+		if !answered {
+			if templates.Reply == nil {
+				fmt.Fprintf(out, "%v bytes from %v: icmp_seq=%v No response\n", 0, arg.Target.IP, i)
+			}
+			report(core.Event{Type: core.EventTimeout, Target: arg.Target.IP.String(), Seq: i})
+		}
+	}
+	c.Close()
+
+	summarize(arg.Target.IP.String())
+	exit(arg.Target.IP.String())
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/erriapo/goping/thirdparty"
+	"github.com/erriapo/stats"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"io"
+	"io/ioutil"
+	"net"
+	"sort"
+	"time"
+)
+
+// ErrNoCapture means goping analyze was not given a capture file.
+var ErrNoCapture = errors.New("no capture file")
+
+// AnalyzeArg holds the command line arguments of goping analyze.
+type AnalyzeArg struct {
+	Help   bool
+	Target string
+	Window time.Duration
+	Files  []string
+}
+
+// ParseAnalyzeArgs parses the arguments following "goping analyze".
+func ParseAnalyzeArgs(options []string) (*AnalyzeArg, error) {
+	bucket := new(AnalyzeArg)
+
+	f := flag.NewFlagSet("goping analyze", flag.ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.BoolVar(&bucket.Help, "h", false, "")
+	f.StringVar(&bucket.Target, "target", "", "")
+	f.DurationVar(&bucket.Window, "W", DefaultWindow, "")
+
+	if err := f.Parse(options); err != nil {
+		return nil, err
+	}
+	if bucket.Help {
+		return bucket, nil
+	}
+	if bucket.Window <= 0 {
+		return nil, ErrBadInterval
+	}
+	bucket.Files = f.Args()
+	if len(bucket.Files) == 0 {
+		return nil, ErrNoCapture
+	}
+	if bucket.Target != "" && net.ParseIP(bucket.Target).To4() == nil {
+		return nil, ErrUnknownHost
+	}
+	return bucket, nil
+}
+
+// TargetAnalysis is what a capture tells about one target.
+// Round trip times are in milliseconds.
+type TargetAnalysis struct {
+	Target     string
+	Counter    *Counter
+	Sink       *stats.WelfordSink
+	RTTs       *Quantiles
+	Buckets    []uint64
+	Duplicates uint64
+	Late       uint64
+	errors     map[icmpError]uint64
+}
+
+// Analysis replays captured packets through the Matcher of a live run.
+type Analysis struct {
+	targets map[string]*TargetAnalysis
+	matcher *Matcher
+	// Unmatched counts replies & errors for requests outside the capture.
+	Unmatched uint64
+}
+
+// NewAnalysis constructs an empty Analysis counting the
+// replies within window of their request, like a live run.
+func NewAnalysis(window time.Duration) *Analysis {
+	return &Analysis{
+		targets: make(map[string]*TargetAnalysis),
+		matcher: NewMatcher(window),
+	}
+}
+
+// Target returns the statistics of target, creating them as needed.
+func (a *Analysis) Target(target string) *TargetAnalysis {
+	t, ok := a.targets[target]
+	if !ok {
+		t = &TargetAnalysis{
+			Target:  target,
+			Counter: NewCounter(),
+			Sink:    stats.NewSink(),
+			RTTs:    NewQuantiles(),
+			Buckets: make([]uint64, len(RTTBuckets)+1),
+			errors:  make(map[icmpError]uint64),
+		}
+		a.targets[target] = t
+	}
+	return t
+}
+
+// Add replays one packet. Anything but ICMP over IPv4 is ignored.
+func (a *Analysis) Add(p CapturedPacket) {
+	b := p.Data
+	hl := int(b[0]&0x0f) << 2
+	if hl < ipv4.HeaderLen || len(b) < hl || b[9] != 1 {
+		return
+	}
+	if total := int(binary.BigEndian.Uint16(b[2:4])); total >= hl && total < len(b) {
+		// Ethernet pads short frames
+		b = b[:total]
+	}
+	src, dst := net.IP(b[12:16]), net.IP(b[16:20])
+	rm, err := icmp.ParseMessage(1, b[hl:])
+	if err != nil {
+		return
+	}
+
+	if IsEchoRequest(rm.Type) {
+		if a.matcher.Sent(dst, rm, p.Time) {
+			a.Target(dst.String()).Counter.OnSent()
+		}
+		return
+	}
+
+	m, verdict := a.matcher.Match(rm, src, p.Time)
+	switch verdict {
+	case Ignored:
+		return
+	case Unmatched:
+		a.Unmatched++
+		return
+	}
+	t := a.Target(m.Target)
+	switch verdict {
+	case Duplicate:
+		t.Duplicates++
+		return
+	case Late:
+		t.Late++
+		return
+	}
+	if m.Error {
+		t.Counter.NoteAnError()
+		if typ, ok := rm.Type.(ipv4.ICMPType); ok {
+			t.errors[icmpError{int(typ), rm.Code}]++
+		}
+		return
+	}
+	t.Counter.OnReception()
+	rtt := m.RTT
+	_ = t.Sink.Push(nanoToMilli(rtt))
+	t.RTTs.Push(nanoToMilli(rtt))
+	i := 0
+	for i < len(RTTBuckets) && rtt.Seconds() > RTTBuckets[i] {
+		i++
+	}
+	t.Buckets[i]++
+}
+
+func nanoToMilli(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / float64(time.Millisecond)
+}
+
+// Targets returns the analysed targets in order.
+func (a *Analysis) Targets() []*TargetAnalysis {
+	targets := make([]*TargetAnalysis, 0, len(a.targets))
+	for _, t := range a.targets {
+		targets = append(targets, t)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Target < targets[j].Target })
+	return targets
+}
+
+// Render writes ping style statistics of every target.
+func (a *Analysis) Render(w io.Writer) {
+	for _, t := range a.Targets() {
+		t.Render(w)
+	}
+	if a.Unmatched > 0 {
+		fmt.Fprintf(w, "\n%d replies or errors without a request in the capture\n", a.Unmatched)
+	}
+}
+
+// Render writes the statistics of t.
+func (t *TargetAnalysis) Render(w io.Writer) {
+	t.Counter.Render(w, fmt.Sprintf("\n--- %s capture statistics ---", t.Target))
+	if t.Duplicates > 0 {
+		fmt.Fprintf(w, "%d duplicates\n", t.Duplicates)
+	}
+	if t.Late > 0 {
+		fmt.Fprintf(w, "%d late replies\n", t.Late)
+	}
+	if t.Sink.Count() > 0 {
+		fmt.Fprintf(w, "%s\n", thirdparty.Format(t.Sink))
+		fmt.Fprintf(w, "%s\n", FormatPercentiles(t.RTTs))
+		fmt.Fprintf(w, "rtt distribution:\n")
+		for i, n := range t.Buckets {
+			if n == 0 {
+				continue
+			}
+			if i < len(RTTBuckets) {
+				fmt.Fprintf(w, "  <= %vms: %d\n", thirdparty.ToFixed(RTTBuckets[i]*1000, 3), n)
+			} else {
+				fmt.Fprintf(w, "  > %vms: %d\n", thirdparty.ToFixed(RTTBuckets[len(RTTBuckets)-1]*1000, 3), n)
+			}
+		}
+	}
+	keys := make([]icmpError, 0, len(t.errors))
+	for k := range t.errors {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].typ < keys[j].typ || keys[i].typ == keys[j].typ && keys[i].code < keys[j].code
+	})
+	for _, k := range keys {
+		fmt.Fprintf(w, "icmp type %d code %d: %d errors\n", k.typ, k.code, t.errors[k])
+	}
+}
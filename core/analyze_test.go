@@ -0,0 +1,116 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAnalyzeArgs(t *testing.T) {
+	if _, err := ParseAnalyzeArgs([]string{}); err != ErrNoCapture {
+		t.Errorf("expected %v ; got %v", ErrNoCapture, err)
+	}
+	if _, err := ParseAnalyzeArgs([]string{"--target", "www.usenix.org", "a.pcap"}); err != ErrUnknownHost {
+		t.Errorf("expected %v ; got %v", ErrUnknownHost, err)
+	}
+	arg, err := ParseAnalyzeArgs([]string{"--target", "192.0.2.1", "a.pcap", "b.pcapng"})
+	if err != nil || arg.Target != "192.0.2.1" || arg.Window != DefaultWindow || !cmp.Equal(arg.Files, []string{"a.pcap", "b.pcapng"}) {
+		t.Errorf("unexpected %+v %v", arg, err)
+	}
+	if arg, err = ParseAnalyzeArgs([]string{"-W", "2s", "a.pcap"}); err != nil || arg.Window != 2*time.Second {
+		t.Errorf("unexpected %+v %v", arg, err)
+	}
+	if _, err := ParseAnalyzeArgs([]string{"-W", "0s", "a.pcap"}); err != ErrBadInterval {
+		t.Errorf("expected %v ; got %v", ErrBadInterval, err)
+	}
+}
+
+func TestAnalyzeCapture(t *testing.T) {
+	var b bytes.Buffer
+	p, _ := NewPcapWriter(&b)
+	here, there := net.IPv4(192, 0, 2, 2), net.IPv4(192, 0, 2, 1)
+	start := time.Unix(1500000000, 0)
+	message := func(typ icmp.Type, body icmp.MessageBody) []byte {
+		wb, _ := (&icmp.Message{Type: typ, Body: body}).Marshal(nil)
+		return wb
+	}
+	request := func(seq int) []byte {
+		return message(ipv4.ICMPTypeEcho, &icmp.Echo{ID: 9, Seq: seq, Data: []byte("hello")})
+	}
+	at := func(ms int) time.Time {
+		return start.Add(time.Duration(ms) * time.Millisecond)
+	}
+
+	quoted, _ := NewIPv4Header(there, nil, 13).Marshal()
+	quoted = append(quoted, request(3)[:8]...)
+	_ = p.WriteICMP(at(0), here, there, 64, request(1), true, "")
+	_ = p.WriteICMP(at(3), there, here, 57, message(ipv4.ICMPTypeEchoReply, &icmp.Echo{ID: 9, Seq: 1, Data: []byte("hello")}), false, "")
+	_ = p.WriteICMP(at(4), there, here, 57, message(ipv4.ICMPTypeEchoReply, &icmp.Echo{ID: 9, Seq: 1, Data: []byte("hello")}), false, "")
+	_ = p.WriteICMP(at(1000), here, there, 64, request(2), true, "")
+	_ = p.WriteICMP(at(2000), here, there, 64, request(3), true, "")
+	_ = p.WriteICMP(at(2010), net.IPv4(198, 51, 100, 1), here, 250, message(ipv4.ICMPTypeDestinationUnreachable, &icmp.DstUnreach{Data: quoted}), false, "")
+	_ = p.WriteICMP(at(2020), there, here, 57, message(ipv4.ICMPTypeEchoReply, &icmp.Echo{ID: 9, Seq: 77}), false, "")
+	// an echo reply after the error is no duplicate
+	_ = p.WriteICMP(at(2030), there, here, 57, message(ipv4.ICMPTypeEchoReply, &icmp.Echo{ID: 9, Seq: 3, Data: []byte("hello")}), false, "")
+
+	packets, err := ReadCapture(&b)
+	if err != nil || len(packets) != 8 {
+		t.Fatalf("expected 8 packets ; got %v %v", len(packets), err)
+	}
+	if !packets[1].Time.Equal(at(3)) {
+		t.Errorf("expected %v ; got %v", at(3), packets[1].Time)
+	}
+	a := NewAnalysis(DefaultWindow)
+	for _, packet := range packets {
+		a.Add(packet)
+	}
+	target := a.Target("192.0.2.1")
+	sent, recvd, errs := target.Counter.totals()
+	if sent != 3 || recvd != 2 || errs != 1 || target.Duplicates != 1 || a.Unmatched != 1 {
+		t.Errorf("unexpected %v/%v/%v duplicates %v unmatched %v", sent, recvd, errs, target.Duplicates, a.Unmatched)
+	}
+	if target.Sink.Count() != 2 || target.Buckets[3] != 1 || target.Buckets[6] != 1 {
+		t.Errorf("expected 3ms & 30ms round trips ; got %v %v", target.Sink.Count(), target.Buckets)
+	}
+	var out bytes.Buffer
+	a.Render(&out)
+	for _, line := range []string{"3 packets transmitted, 2 received, +1 errors, 33% packet loss", "1 duplicates",
+		"  <= 5ms: 1", "icmp type 3 code 0: 1 errors"} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("missing <%v> in\n%v", line, out.String())
+		}
+	}
+}
+
+func TestAnalyzeLateReply(t *testing.T) {
+	here, there := net.IPv4(192, 0, 2, 2), net.IPv4(192, 0, 2, 1)
+	start := time.Unix(1500000000, 0)
+	packet := func(ms int, src, dst net.IP, typ icmp.Type) CapturedPacket {
+		wb, _ := (&icmp.Message{Type: typ, Body: &icmp.Echo{ID: 9, Seq: 1}}).Marshal(nil)
+		h, _ := NewIPv4Header(dst, nil, len(wb)).Marshal()
+		copy(h[12:16], src.To4())
+		return CapturedPacket{Time: start.Add(time.Duration(ms) * time.Millisecond), Data: append(h, wb...)}
+	}
+	a := NewAnalysis(time.Second)
+	a.Add(packet(0, here, there, ipv4.ICMPTypeEcho))
+	a.Add(packet(1500, there, here, ipv4.ICMPTypeEchoReply))
+	target := a.Target("192.0.2.1")
+	sent, recvd, _ := target.Counter.totals()
+	if sent != 1 || recvd != 0 || target.Late != 1 {
+		t.Errorf("unexpected %v/%v late %v", sent, recvd, target.Late)
+	}
+	var out bytes.Buffer
+	a.Render(&out)
+	if !strings.Contains(out.String(), "1 late replies\n") {
+		t.Errorf("missing late replies in\n%v", out.String())
+	}
+}
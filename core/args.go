@@ -17,10 +17,11 @@ import (
 	"log"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"text/template"
-	//"time"
+	"time"
 )
 
 var lookupIPfunc = net.LookupIP
@@ -70,8 +71,26 @@ func (c *Cache) Reverse(peer net.Addr) (string, error) {
 // ParseAddr returns the IPv4 address.
 // Potentially could be a nil return value.
 func ParseAddr(input string) *net.IPAddr {
+	return parseAddr(input, func(candidate net.IP) bool {
+		return candidate.To4() != nil
+	})
+}
+
+// ParseAddr6 returns the IPv6 address.
+// Potentially could be a nil return value.
+func ParseAddr6(input string) *net.IPAddr {
+	return parseAddr(input, func(candidate net.IP) bool {
+		return candidate.To4() == nil
+	})
+}
+
+func parseAddr(input string, wanted func(net.IP) bool) *net.IPAddr {
 	ip := net.ParseIP(input)
 	if ip != nil {
+		// a literal of the other family is no more usable than a bad name
+		if !wanted(ip) {
+			return nil
+		}
 		return &net.IPAddr{IP: ip}
 	}
 
@@ -86,8 +105,8 @@ func ParseAddr(input string) *net.IPAddr {
 
 	var result net.IP
 	for _, candidate := range candidates {
-		// First ipv4 address wins out.
-		if candidate.To4() != nil {
+		// First matching address wins out.
+		if wanted(candidate) {
 			result = candidate
 			break
 		}
@@ -121,21 +140,129 @@ var ErrNoTarget = errors.New("Missing target")
 // ErrBadCount signifies that count packets must be greater than or equal to 1.
 var ErrBadCount = errors.New("bad number of packets to transmit")
 
+// ErrBadTTL means the TTL is outside 1 to 255.
+var ErrBadTTL = errors.New("ttl out of range")
+
+// ErrNoCSV means a CSV summary was requested without any CSV output.
+var ErrNoCSV = errors.New("--csv-summary needs --csv or --format csv")
+
+// ErrBadOutage means --down-after or --up-after is less than 1.
+var ErrBadOutage = errors.New("--down-after & --up-after must be at least 1")
+
+// ErrNoCheck means -w or -c thresholds were given without --check.
+var ErrNoCheck = errors.New("-w & -c thresholds need --check")
+
+// ErrCheckFormat means --check was combined with a --format,
+// whose output would mix with the plugin line on stdout.
+var ErrCheckFormat = errors.New("--check cannot be used with --format")
+
+// ErrBadMaxLoss means --max-loss is not a percentage.
+var ErrBadMaxLoss = errors.New("--max-loss must be between 0 & 100")
+
+// ErrNeighborIdent means --neighbor was given without -e naming an
+// address; interfaces are only looked up by address in a neighbor table.
+var ErrNeighborIdent = errors.New("--neighbor needs -e with an IP address")
+
+// ErrProbeFamily means -e was given an IPv6 target; the ping sends ICMPv4 only.
+var ErrProbeFamily = errors.New("-e needs an IPv4 target, the ping sends ICMPv4 only")
+
+// ErrConfigProtocol means the --config file sets protocol icmp6 for the
+// target of a ping; only goping serve sends ICMPv6 echo requests.
+var ErrConfigProtocol = errors.New("protocol icmp6 of --config needs goping serve, the ping sends ICMPv4 only")
+
+// ErrModes means more than one of the mutually exclusive modes was asked for.
+var ErrModes = errors.New("only one of --arp, --nd, -b & -e can be given")
+
+// ErrPcapMode means --pcap was combined with --arp, --nd or -b,
+// whose packets it does not record.
+var ErrPcapMode = errors.New("--pcap cannot be used with --arp, --nd or -b")
+
+// ErrBadWindows means --windows is not a list of positive durations.
+var ErrBadWindows = errors.New("bad --windows, expected e.g. 1m,5m,15m")
+
 // Arg holds the command line arguments.
 type Arg struct {
-	Host      string
-	Interface string
-	Help      bool
-	Extra     bool
-	Count     uint64
+	Host         string
+	Interface    string
+	Help         bool
+	Extra        bool
+	Count        uint64
+	Interval     time.Duration
+	Timeout      time.Duration
+	Size         int
+	Probe        string
+	Neighbor     bool
+	Record       bool
+	Timestamp    string
+	ARP          bool
+	ND           bool
+	Broadcast    bool
+	TTL          int
+	Format       string
+	CSV          string
+	CSVSummary   string
+	Influx       string
+	StatsD       string
+	OTLP         string
+	Pcap         string
+	DownAfter    int
+	UpAfter      int
+	PushEvery    time.Duration
+	SummaryEvery time.Duration
+	Windows      []time.Duration
+	Check        bool
+	Warning      string
+	Critical     string
+	MaxLoss      float64
+
+	ReplyTemplate   string
+	SummaryTemplate string
+	StatsTemplate   string
+	Target          *net.IPAddr
+	CNAME           string
 }
 
 const defaultInterface = "0.0.0.0"
 
+// countOrCritical is -c: the count or, as --check borrows check_ping's
+// -w & -c, the critical thresholds when it holds a comma.
+type countOrCritical struct {
+	count    *uint64
+	counted  *bool
+	critical *string
+}
+
+func (v countOrCritical) String() string {
+	return ""
+}
+
+func (v countOrCritical) Set(s string) error {
+	if strings.Contains(s, ",") {
+		*v.critical = s
+		return nil
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*v.count, *v.counted = n, true
+	return nil
+}
+
 // ParseOption parses command line arguments
 func ParseOption(options []string) (bool, bool, uint64, *net.IPAddr, string, string, error) {
+	bucket, err := ParseArgs(options)
+	if bucket == nil {
+		return false, false, 0, nil, "", defaultInterface, err
+	}
+	return bucket.Help, bucket.Extra, bucket.Count, bucket.Target, bucket.CNAME, bucket.Interface, err
+}
+
+// ParseArgs parses command line arguments into an Arg.
+// On error the returned Arg may be nil.
+func ParseArgs(options []string) (*Arg, error) {
 	if len(options) == 0 {
-		return false, false, 0, nil, "", defaultInterface, ErrUnknownHost
+		return nil, ErrUnknownHost
 	}
 	bucket := new(Arg)
 
@@ -144,10 +271,41 @@ func ParseOption(options []string) (bool, bool, uint64, *net.IPAddr, string, str
 	f.BoolVar(&bucket.Help, "h", false, "")
 	f.BoolVar(&bucket.Extra, "v", false, "")
 	f.StringVar(&bucket.Interface, "I", "0.0.0.0", "")
-	f.Uint64Var(&bucket.Count, "c", 5, "")
+	bucket.Count = 5
+	var counted bool
+	f.Var(countOrCritical{&bucket.Count, &counted, &bucket.Critical}, "c", "")
+	// like a Module, without flags of their own yet
+	bucket.Interval, bucket.Timeout, bucket.Size = time.Second, DefaultWindow, 56
+	f.StringVar(&bucket.Probe, "e", "", "")
+	f.BoolVar(&bucket.Record, "R", false, "")
+	f.StringVar(&bucket.Timestamp, "T", "", "")
+	f.BoolVar(&bucket.ARP, "arp", false, "")
+	f.BoolVar(&bucket.ND, "nd", false, "")
+	f.BoolVar(&bucket.Neighbor, "neighbor", false, "")
+	f.BoolVar(&bucket.Broadcast, "b", false, "")
+	f.IntVar(&bucket.TTL, "t", 0, "")
+	f.StringVar(&bucket.Format, "format", FormatText, "")
+	f.StringVar(&bucket.CSV, "csv", "", "")
+	f.StringVar(&bucket.CSVSummary, "csv-summary", "", "")
+	f.StringVar(&bucket.Influx, "influx", "", "")
+	f.StringVar(&bucket.StatsD, "statsd", "", "")
+	f.StringVar(&bucket.OTLP, "otlp", "", "")
+	f.StringVar(&bucket.Pcap, "pcap", "", "")
+	f.IntVar(&bucket.DownAfter, "down-after", 3, "")
+	f.IntVar(&bucket.UpAfter, "up-after", 1, "")
+	f.DurationVar(&bucket.PushEvery, "push-every", 0, "")
+	f.DurationVar(&bucket.SummaryEvery, "summary-every", 0, "")
+	windows := f.String("windows", "1m,5m,15m", "")
+	f.BoolVar(&bucket.Check, "check", false, "")
+	f.StringVar(&bucket.Warning, "w", "", "")
+	f.Float64Var(&bucket.MaxLoss, "max-loss", 100, "")
+	config := f.String("config", "", "")
+	f.StringVar(&bucket.ReplyTemplate, "reply-template", "", "")
+	f.StringVar(&bucket.SummaryTemplate, "summary-template", "", "")
+	f.StringVar(&bucket.StatsTemplate, "stats-template", "", "")
 
 	if err := f.Parse(options); err != nil {
-		return false, false, 0, nil, "", defaultInterface, err
+		return nil, err
 	}
 
 	if bucket.Extra {
@@ -155,29 +313,134 @@ func ParseOption(options []string) (bool, bool, uint64, *net.IPAddr, string, str
 	}
 
 	if bucket.Help {
-		return bucket.Help, bucket.Extra, 0, nil, "", defaultInterface, nil
+		return &Arg{Help: true, Extra: bucket.Extra, Interface: defaultInterface}, nil
 	}
 
 	if len(f.Args()) == 0 {
-		return false, false, 0, nil, "", defaultInterface, ErrNoTarget
+		return nil, ErrNoTarget
 	} else {
 		bucket.Host = f.Args()[0]
 	}
 
+	if *config != "" {
+		c, err := LoadConfig(*config)
+		if err != nil {
+			return nil, err
+		}
+		settings := c.Settings(bucket.Host)
+		if settings.Protocol != "icmp" {
+			return nil, ErrConfigProtocol
+		}
+		// -c takes precedence over the file
+		if c.Sets(bucket.Host, "count") && !counted {
+			bucket.Count = settings.Count
+		}
+		if c.Sets(bucket.Host, "interval") {
+			bucket.Interval = settings.Interval
+		}
+		if c.Sets(bucket.Host, "timeout") {
+			bucket.Timeout = settings.Timeout
+		}
+		if c.Sets(bucket.Host, "size") {
+			bucket.Size = settings.Size
+		}
+	}
+
 	if bucket.Count == 0 {
-		return false, false, 0, nil, "", defaultInterface, ErrBadCount
+		return nil, ErrBadCount
+	}
+
+	if _, err := NewOutput(ioutil.Discard, bucket.Format); err != nil {
+		return nil, err
+	}
+
+	if bucket.CSVSummary != "" && bucket.CSV == "" && bucket.Format != FormatCSV {
+		return nil, ErrNoCSV
+	}
+
+	if _, err := NewTemplates(bucket); err != nil {
+		return nil, err
+	}
+
+	if bucket.TTL < 0 || bucket.TTL > 255 {
+		return nil, ErrBadTTL
+	}
+
+	if bucket.DownAfter < 1 || bucket.UpAfter < 1 {
+		return nil, ErrBadOutage
+	}
+
+	if bucket.PushEvery < 0 || bucket.SummaryEvery < 0 {
+		return nil, ErrBadInterval
+	}
+
+	spans, err := ParseWindows(*windows)
+	if err != nil {
+		return nil, err
+	}
+	bucket.Windows = spans
+
+	if bucket.MaxLoss < 0 || bucket.MaxLoss > 100 {
+		return nil, ErrBadMaxLoss
+	}
+
+	if bucket.Check && bucket.Format != FormatText {
+		return nil, ErrCheckFormat
+	}
+
+	if bucket.Check {
+		if _, err := NewCheck(bucket.Warning, bucket.Critical); err != nil {
+			return nil, err
+		}
+	} else if bucket.Warning != "" || bucket.Critical != "" {
+		return nil, ErrNoCheck
+	}
+
+	if _, err := NewIPOptions(bucket.Record, bucket.Timestamp); err != nil {
+		return nil, err
+	}
+
+	if bucket.Neighbor && net.ParseIP(bucket.Probe) == nil {
+		return nil, ErrNeighborIdent
+	}
+
+	if ip := net.ParseIP(bucket.Host); bucket.Probe != "" && ip != nil && ip.To4() == nil {
+		return nil, ErrProbeFamily
+	}
+
+	modes := 0
+	for _, on := range []bool{bucket.ARP, bucket.ND, bucket.Broadcast, bucket.Probe != ""} {
+		if on {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return nil, ErrModes
+	}
+
+	if bucket.Pcap != "" && (bucket.ARP || bucket.ND || bucket.Broadcast) {
+		return nil, ErrPcapMode
+	}
+
+	if bucket.ND && bucket.Interface == defaultInterface {
+		return nil, ErrNoInterface
 	}
 
 	//start := time.Now()
 	fmt.Fprintf(os.Stderr, ".\n")
-	ipAddr := ParseAddr(bucket.Host)
+	if bucket.ND {
+		bucket.Target = ParseAddr6(bucket.Host)
+	} else {
+		bucket.Target = ParseAddr(bucket.Host)
+	}
 	//elapsed := time.Since(start)
 	//fmt.Fprintf(os.Stderr, "%v\n\n", elapsed)
 
-	if ipAddr == nil {
-		return false, bucket.Extra, 0, nil, "", bucket.Interface, ErrUnknownHost
+	if bucket.Target == nil {
+		return &Arg{Extra: bucket.Extra, Interface: bucket.Interface}, ErrUnknownHost
 	}
-	return bucket.Help, bucket.Extra, bucket.Count, ipAddr, TryConvertPunycode(GetCNAME(bucket.Host)), bucket.Interface, nil
+	bucket.CNAME = TryConvertPunycode(GetCNAME(bucket.Host))
+	return bucket, nil
 }
 
 const step uint64 = 1
@@ -225,6 +488,14 @@ func (c *Counter) NoteAnError() {
 	c.Errors += 1
 }
 
+// totals reads the counters under the lock.
+func (c *Counter) totals() (sent, recvd, errors uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.Sent, c.Recvd, c.Errors
+}
+
 func (c *Counter) gotError() {
 	c.lock.Lock()
 	defer c.lock.Unlock()
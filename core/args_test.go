@@ -6,10 +6,10 @@ package core
 import (
 	"bytes"
 	"fmt"
+	"github.com/google/go-cmp/cmp"
 	"net"
 	"os"
 	"testing"
-	"github.com/google/go-cmp/cmp"
 )
 
 func TestMain(m *testing.M) {
@@ -87,6 +87,19 @@ func TestParseZeroOptions(t *testing.T) {
 	}
 }
 
+func TestParseModes(t *testing.T) {
+	for _, options := range [][]string{
+		{"--arp", "--nd", "-I", "eth0", "fe80::1"},
+		{"--arp", "-b", "192.0.2.255"},
+		{"--arp", "-e", "eth0", "192.0.2.1"},
+		{"-b", "-e", "eth0", "192.0.2.255"},
+	} {
+		if _, err := ParseArgs(options); err != ErrModes {
+			t.Errorf("%v: expected %v ; got %v", options, ErrModes, err)
+		}
+	}
+}
+
 func TestReturnOnlyIPv4(t *testing.T) {
 	ipv4 := ParseAddr("localhost")
 	fmt.Printf("%v\n", ipv4)
@@ -160,11 +173,11 @@ func TestPunycodeConversion(t *testing.T) {
 }
 
 func TestChoosePeer(t *testing.T) {
-        // ChoosePeer(hostFQDN string, host *net.IPAddr, hostErr error, peerFQDN string, peer net.Addr, peerErr error)
+	// ChoosePeer(hostFQDN string, host *net.IPAddr, hostErr error, peerFQDN string, peer net.Addr, peerErr error)
 	expected0 := Peer{FQDN: "Unknown", IP: "?.?.?.?"}
 	peer0 := ChoosePeer("", nil, nil, "", nil, nil)
-	if ! cmp.Equal(peer0, expected0) {
-			t.Errorf("ChoosePeer: expected %v, actual %v", expected0, peer0)
+	if !cmp.Equal(peer0, expected0) {
+		t.Errorf("ChoosePeer: expected %v, actual %v", expected0, peer0)
 	}
 }
 
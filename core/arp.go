@@ -0,0 +1,116 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"time"
+)
+
+// ARP operations (RFC 826)
+const (
+	ARPRequest = 1
+	ARPReply   = 2
+)
+
+const arpLen = 28
+
+// ErrNotARP means the frame is not an Ethernet/IPv4 ARP packet.
+var ErrNotARP = errors.New("not an IPv4 over Ethernet ARP packet")
+
+// ErrNotOnLink means no local interface shares a subnet with the target.
+var ErrNotOnLink = errors.New("target is not on a directly connected network")
+
+// ErrNotSupported means the mode is unavailable on this platform.
+var ErrNotSupported = errors.New("not supported on this platform")
+
+// ARPPacket is an IPv4 over Ethernet ARP message.
+type ARPPacket struct {
+	Op        int
+	SenderMAC net.HardwareAddr
+	SenderIP  net.IP
+	TargetMAC net.HardwareAddr
+	TargetIP  net.IP
+}
+
+// ARPResponse is one answer to an ARP request.
+type ARPResponse struct {
+	MAC net.HardwareAddr
+	RTT time.Duration
+}
+
+// Marshal returns the 28 byte wire format.
+func (p *ARPPacket) Marshal() []byte {
+	b := make([]byte, arpLen)
+	binary.BigEndian.PutUint16(b[0:2], 1)      // Ethernet
+	binary.BigEndian.PutUint16(b[2:4], 0x0800) // IPv4
+	b[4], b[5] = 6, 4
+	binary.BigEndian.PutUint16(b[6:8], uint16(p.Op))
+	copy(b[8:14], p.SenderMAC)
+	copy(b[14:18], p.SenderIP.To4())
+	copy(b[18:24], p.TargetMAC)
+	copy(b[24:28], p.TargetIP.To4())
+	return b
+}
+
+// Answers tells whether p is a reply from target to our request,
+// sent by src from mac; gratuitous ARP & replies to other hosts are not.
+func (p *ARPPacket) Answers(target, src net.IP, mac net.HardwareAddr) bool {
+	return p.Op == ARPReply && p.SenderIP.Equal(target) &&
+		p.TargetIP.Equal(src) && bytes.Equal(p.TargetMAC, mac)
+}
+
+// ParseARP decodes an ARP packet without its Ethernet header.
+func ParseARP(b []byte) (*ARPPacket, error) {
+	if len(b) < arpLen ||
+		binary.BigEndian.Uint16(b[0:2]) != 1 ||
+		binary.BigEndian.Uint16(b[2:4]) != 0x0800 ||
+		b[4] != 6 || b[5] != 4 {
+		return nil, ErrNotARP
+	}
+	return &ARPPacket{
+		Op:        int(binary.BigEndian.Uint16(b[6:8])),
+		SenderMAC: net.HardwareAddr(append([]byte(nil), b[8:14]...)),
+		SenderIP:  net.IP(append([]byte(nil), b[14:18]...)),
+		TargetMAC: net.HardwareAddr(append([]byte(nil), b[18:24]...)),
+		TargetIP:  net.IP(append([]byte(nil), b[24:28]...)),
+	}, nil
+}
+
+// OnLinkInterface finds the interface whose IPv4 subnet contains target.
+// When name is not empty only that interface is considered.
+// It returns the interface and our address on it.
+func OnLinkInterface(name string, target net.IP) (*net.Interface, net.IP, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range interfaces {
+		k := &interfaces[i]
+		if name != "" && k.Name != name {
+			continue
+		}
+		if k.Flags&net.FlagUp == 0 || len(k.HardwareAddr) != 6 {
+			continue
+		}
+		addresses, err := k.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, h := range addresses {
+			ipnet, ok := h.(*net.IPNet)
+			if !ok || ipnet.IP.To4() == nil {
+				continue
+			}
+			if ipnet.Contains(target) {
+				return k, ipnet.IP.To4(), nil
+			}
+		}
+	}
+	return nil, nil, ErrNotOnLink
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"net"
+	"testing"
+)
+
+func TestARPRoundTrip(t *testing.T) {
+	mac, _ := net.ParseMAC("02:00:5e:10:00:01")
+	req := &ARPPacket{Op: ARPReply, SenderMAC: mac, SenderIP: net.IPv4(192, 168, 1, 1),
+		TargetMAC: mac, TargetIP: net.IPv4(192, 168, 1, 2)}
+	got, err := ParseARP(req.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(got.SenderMAC, mac) || !got.SenderIP.Equal(req.SenderIP) || got.Op != ARPReply {
+		t.Errorf("expected %+v ; got %+v", req, got)
+	}
+	if _, err := ParseARP(make([]byte, 27)); err != ErrNotARP {
+		t.Errorf("expected %v ; got %v", ErrNotARP, err)
+	}
+}
+
+func TestARPAnswers(t *testing.T) {
+	ours, _ := net.ParseMAC("02:00:5e:10:00:02")
+	theirs, _ := net.ParseMAC("02:00:5e:10:00:01")
+	target, src := net.IPv4(192, 168, 1, 1), net.IPv4(192, 168, 1, 2)
+	for _, c := range []struct {
+		reply    ARPPacket
+		expected bool
+	}{
+		{ARPPacket{Op: ARPReply, SenderMAC: theirs, SenderIP: target, TargetMAC: ours, TargetIP: src}, true},
+		// gratuitous ARP
+		{ARPPacket{Op: ARPReply, SenderMAC: theirs, SenderIP: target, TargetMAC: make(net.HardwareAddr, 6), TargetIP: target}, false},
+		// the reply to another host
+		{ARPPacket{Op: ARPReply, SenderMAC: theirs, SenderIP: target, TargetMAC: theirs, TargetIP: net.IPv4(192, 168, 1, 3)}, false},
+		{ARPPacket{Op: ARPRequest, SenderMAC: theirs, SenderIP: target, TargetMAC: ours, TargetIP: src}, false},
+	} {
+		if got := c.reply.Answers(target, src, ours); got != c.expected {
+			t.Errorf("%+v: expected %v ; got %v", c.reply, c.expected, got)
+		}
+	}
+}
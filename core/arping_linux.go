@@ -0,0 +1,95 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package core
+
+import (
+	"net"
+	"syscall"
+	"time"
+)
+
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
+
+// ARPPinger sends ARP requests out of one interface
+// over an AF_PACKET socket.
+type ARPPinger struct {
+	fd     int
+	ifi    *net.Interface
+	src    net.IP
+	target net.IP
+}
+
+// NewARPPinger opens an AF_PACKET socket bound to ifi.
+func NewARPPinger(ifi *net.Interface, src, target net.IP) (*ARPPinger, error) {
+	proto := htons(syscall.ETH_P_ARP)
+	fd, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_DGRAM, int(proto))
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Bind(fd, &syscall.SockaddrLinklayer{Protocol: proto, Ifindex: ifi.Index}); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return &ARPPinger{fd: fd, ifi: ifi, src: src.To4(), target: target.To4()}, nil
+}
+
+// Ping broadcasts one ARP request and collects every reply
+// for the target that arrives within window.
+func (p *ARPPinger) Ping(window time.Duration) ([]ARPResponse, error) {
+	req := &ARPPacket{
+		Op:        ARPRequest,
+		SenderMAC: p.ifi.HardwareAddr,
+		SenderIP:  p.src,
+		TargetMAC: make(net.HardwareAddr, 6),
+		TargetIP:  p.target,
+	}
+	to := &syscall.SockaddrLinklayer{
+		Protocol: htons(syscall.ETH_P_ARP),
+		Ifindex:  p.ifi.Index,
+		Halen:    6,
+		Addr:     [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	}
+
+	start := time.Now()
+	if err := syscall.Sendto(p.fd, req.Marshal(), 0, to); err != nil {
+		return nil, err
+	}
+
+	var responses []ARPResponse
+	deadline := start.Add(window)
+	b := make([]byte, 128)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return responses, nil
+		}
+		tv := syscall.NsecToTimeval(remaining.Nanoseconds())
+		if err := syscall.SetsockoptTimeval(p.fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+			return responses, err
+		}
+		n, _, err := syscall.Recvfrom(p.fd, b, 0)
+		if err != nil {
+			if err == syscall.EAGAIN || err == syscall.EINTR {
+				continue
+			}
+			return responses, err
+		}
+		reply, err := ParseARP(b[:n])
+		if err != nil || !reply.Answers(p.target, p.src, p.ifi.HardwareAddr) {
+			continue
+		}
+		responses = append(responses, ARPResponse{MAC: reply.SenderMAC, RTT: time.Since(start)})
+	}
+}
+
+// Close releases the socket.
+func (p *ARPPinger) Close() error {
+	return syscall.Close(p.fd)
+}
@@ -0,0 +1,31 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package core
+
+import (
+	"net"
+	"time"
+)
+
+// ARPPinger is only implemented on Linux.
+type ARPPinger struct{}
+
+// NewARPPinger always fails outside Linux.
+func NewARPPinger(ifi *net.Interface, src, target net.IP) (*ARPPinger, error) {
+	return nil, ErrNotSupported
+}
+
+// Ping is never reached outside Linux.
+func (p *ARPPinger) Ping(window time.Duration) ([]ARPResponse, error) {
+	return nil, ErrNotSupported
+}
+
+// Close is a no-op.
+func (p *ARPPinger) Close() error {
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/thirdparty"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Burst is a run of consecutive lost probes. Start is when the
+// first was reported lost, End when the run was broken by a reply
+// or, for a burst still going on, when the last was reported lost.
+type Burst struct {
+	Length uint64    `json:"length"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+}
+
+// LossBursts is the loss pattern of a run.
+//
+// P & R are the transition probabilities of the Gilbert-Elliott model
+// fitted to the sequence: P from received to lost & R from lost to
+// received. A small R means long bursts, i.e. outages; a large P with
+// a large R means scattered drops, typical of congestion.
+type LossBursts struct {
+	Bursts  uint64         `json:"bursts"`
+	Lengths map[int]uint64 `json:"burst_lengths"`
+	Longest *Burst         `json:"longest_burst,omitempty"`
+	MeanGap float64        `json:"mean_gap_probes"`
+	P       float64        `json:"gilbert_p"`
+	R       float64        `json:"gilbert_r"`
+}
+
+// Bursts follows the sequence of received & lost probes.
+// It is safe for concurrent use.
+type Bursts struct {
+	lock        sync.Mutex
+	have        bool
+	lost        bool
+	current     Burst
+	longest     Burst
+	longestOpen bool
+	lengths     map[int]uint64
+	bursts      uint64
+	gap         uint64
+	gaps        uint64
+	gapSum      uint64
+	transitions [2][2]uint64 // [from lost][to lost]
+}
+
+// NewBursts constructs an empty Bursts.
+func NewBursts() *Bursts {
+	return &Bursts{lengths: make(map[int]uint64)}
+}
+
+// Observe advances the sequence with the outcome of a probe,
+// once per probe; see Repeats.
+func (b *Bursts) Observe(e Event) {
+	lost, ok := Lost(e)
+	if !ok {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.have {
+		b.transitions[lostIndex(b.lost)][lostIndex(lost)]++
+	}
+	switch {
+	case lost && (!b.have || !b.lost):
+		b.current = Burst{Length: 1, Start: e.Time, End: e.Time}
+		b.longestOpen = false
+		b.bursts++
+		if b.bursts > 1 {
+			b.gaps++
+			b.gapSum += b.gap
+		}
+	case lost:
+		b.current.Length++
+		b.current.End = e.Time
+	case b.have && b.lost:
+		b.current.End = e.Time
+		b.endBurst()
+		b.gap = 1
+	default:
+		b.gap++
+	}
+	if lost && (b.longestOpen || b.current.Length > b.longest.Length) {
+		b.longest, b.longestOpen = b.current, true
+	}
+	b.have, b.lost = true, lost
+}
+
+func lostIndex(lost bool) int {
+	if lost {
+		return 1
+	}
+	return 0
+}
+
+func (b *Bursts) endBurst() {
+	b.lengths[int(b.current.Length)]++
+	if b.longestOpen {
+		b.longest.End, b.longestOpen = b.current.End, false
+	}
+}
+
+// Report summarises the sequence so far; nil when nothing was lost.
+func (b *Bursts) Report() *LossBursts {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.bursts == 0 {
+		return nil
+	}
+	r := &LossBursts{Bursts: b.bursts, Lengths: make(map[int]uint64)}
+	for k, v := range b.lengths {
+		r.Lengths[k] = v
+	}
+	if b.lost {
+		// the burst still going on
+		r.Lengths[int(b.current.Length)]++
+	}
+	longest := b.longest
+	r.Longest = &longest
+	if b.gaps > 0 {
+		r.MeanGap = float64(b.gapSum) / float64(b.gaps)
+	}
+	t := b.transitions
+	if n := t[0][0] + t[0][1]; n > 0 {
+		r.P = float64(t[0][1]) / float64(n)
+	}
+	if n := t[1][0] + t[1][1]; n > 0 {
+		r.R = float64(t[1][0]) / float64(n)
+	}
+	return r
+}
+
+// String renders the loss pattern, e.g. "loss bursts: 2 (1x1, 5x1),
+// longest 5 probes from 15:04:05 to 15:04:10, mean gap 12 probes, gilbert p/r = 0.02/0.5"
+func (r *LossBursts) String() string {
+	lengths := make([]int, 0, len(r.Lengths))
+	for k := range r.Lengths {
+		lengths = append(lengths, k)
+	}
+	sort.Ints(lengths)
+	histogram := make([]string, len(lengths))
+	for i, k := range lengths {
+		histogram[i] = fmt.Sprintf("%dx%d", k, r.Lengths[k])
+	}
+	const clock = "15:04:05"
+	return fmt.Sprintf("loss bursts: %d (%s), longest %d probes from %s to %s, mean gap %v probes, gilbert p/r = %v/%v",
+		r.Bursts, strings.Join(histogram, ", "), r.Longest.Length, r.Longest.Start.Format(clock), r.Longest.End.Format(clock),
+		thirdparty.ToFixed(r.MeanGap, 1), thirdparty.ToFixed(r.P, 3), thirdparty.ToFixed(r.R, 3))
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/json"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLossBursts(t *testing.T) {
+	b := NewBursts()
+	if b.Report() != nil {
+		t.Errorf("expected no report without losses")
+	}
+	start := time.Unix(1500000000, 0)
+	// R L R R L L L R R R R L
+	for i, c := range "RLRRLLLRRRRL" {
+		e := Event{Type: EventReply, Seq: i + 1, Time: start.Add(time.Duration(i) * time.Second)}
+		if c == 'L' {
+			e.Type = EventTimeout
+		}
+		b.Observe(e)
+	}
+	r := b.Report()
+	if r.Bursts != 3 || !cmp.Equal(r.Lengths, map[int]uint64{1: 2, 3: 1}) {
+		t.Errorf("unexpected bursts %+v", r)
+	}
+	if r.Longest.Length != 3 || !r.Longest.Start.Equal(start.Add(4*time.Second)) || !r.Longest.End.Equal(start.Add(7*time.Second)) {
+		t.Errorf("unexpected longest burst %+v", r.Longest)
+	}
+	// gaps of 2 & 4 received probes
+	if r.MeanGap != 3 {
+		t.Errorf("expected mean gap 3 ; got %v", r.MeanGap)
+	}
+	// R->L 3 of 7 transitions from R; L->R 2 of 4 from L
+	if r.P != 3.0/7 || r.R != 0.5 {
+		t.Errorf("expected p/r 3/7 & 1/2 ; got %v/%v", r.P, r.R)
+	}
+	if !strings.HasPrefix(r.String(), "loss bursts: 3 (1x2, 3x1), longest 3 probes from ") {
+		t.Errorf("unexpected <%v>", r.String())
+	}
+
+	var s Summary
+	s.Bursts = r
+	doc, _ := json.Marshal(&s)
+	if !strings.Contains(string(doc), `"burst_lengths":{"1":2,"3":1}`) {
+		t.Errorf("unexpected JSON %s", doc)
+	}
+}
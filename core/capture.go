@@ -0,0 +1,213 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"time"
+)
+
+// ErrNotCapture means the file is neither pcap nor pcapng.
+var ErrNotCapture = errors.New("not a pcap or pcapng file")
+
+// ErrTruncatedCapture means a capture file ends in the middle of a record.
+var ErrTruncatedCapture = errors.New("truncated capture")
+
+// ErrBadInterfaceID means a pcapng packet refers to an interface
+// the file does not describe.
+var ErrBadInterfaceID = errors.New("packet of an undescribed interface in capture")
+
+// link types whose IPv4 packets ReadCapture extracts
+const (
+	linkTypeNull     = 0
+	linkTypeEthernet = 1
+	linkTypeRaw      = 101
+	linkTypeLinuxSLL = 113
+	linkTypeSLL2     = 276
+)
+
+// CapturedPacket is an IPv4 packet read from a capture file.
+type CapturedPacket struct {
+	Time time.Time
+	Data []byte
+}
+
+// ReadCapture returns the IPv4 packets of a pcap, e.g. from tcpdump -w,
+// or pcapng file, e.g. from goping --pcap. Other packets are skipped.
+func ReadCapture(r io.Reader) ([]CapturedPacket, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 24 {
+		return nil, ErrNotCapture
+	}
+	if binary.LittleEndian.Uint32(b) == pcapngSectionHeader {
+		return readPcapng(b)
+	}
+	return readPcap(b)
+}
+
+func readPcap(b []byte) ([]CapturedPacket, error) {
+	var order binary.ByteOrder
+	var nano bool
+	switch binary.LittleEndian.Uint32(b) {
+	case 0xa1b2c3d4:
+		order = binary.LittleEndian
+	case 0xa1b23c4d:
+		order, nano = binary.LittleEndian, true
+	case 0xd4c3b2a1:
+		order = binary.BigEndian
+	case 0x4d3cb2a1:
+		order, nano = binary.BigEndian, true
+	default:
+		return nil, ErrNotCapture
+	}
+	linkType := int(order.Uint32(b[20:24]) & 0xffff)
+
+	var packets []CapturedPacket
+	for rest := b[24:]; len(rest) > 0; {
+		if len(rest) < 16 {
+			return packets, ErrTruncatedCapture
+		}
+		sec, frac, n := int64(order.Uint32(rest)), int64(order.Uint32(rest[4:])), int(order.Uint32(rest[8:]))
+		if len(rest) < 16+n {
+			return packets, ErrTruncatedCapture
+		}
+		if !nano {
+			frac *= int64(time.Microsecond)
+		}
+		if ip := linkPayload(linkType, rest[16:16+n]); ip != nil {
+			packets = append(packets, CapturedPacket{Time: time.Unix(sec, frac), Data: ip})
+		}
+		rest = rest[16+n:]
+	}
+	return packets, nil
+}
+
+// pcapngLink is what an Interface Description Block tells about its packets.
+type pcapngLink struct {
+	linkType int
+	unit     float64 // seconds per timestamp tick
+}
+
+func readPcapng(b []byte) ([]CapturedPacket, error) {
+	var order binary.ByteOrder = binary.LittleEndian
+	var links []pcapngLink
+	var packets []CapturedPacket
+	for rest := b; len(rest) > 0; {
+		if len(rest) < 12 {
+			return packets, ErrTruncatedCapture
+		}
+		typ := order.Uint32(rest)
+		if typ == pcapngSectionHeader {
+			// every section may have its own byte order & interfaces
+			switch binary.LittleEndian.Uint32(rest[8:]) {
+			case pcapngByteOrderMagic:
+				order = binary.LittleEndian
+			case 0x4d3c2b1a:
+				order = binary.BigEndian
+			default:
+				return packets, ErrNotCapture
+			}
+			links = nil
+		}
+		length := int(order.Uint32(rest[4:]))
+		if length < 12 || length > len(rest) {
+			return packets, ErrTruncatedCapture
+		}
+		body := rest[8 : length-4]
+		rest = rest[length:]
+
+		switch typ {
+		case pcapngInterface:
+			if len(body) < 8 {
+				return packets, ErrTruncatedCapture
+			}
+			link := pcapngLink{linkType: int(order.Uint16(body)), unit: 1e-6}
+			for opts := body[8:]; len(opts) >= 4; {
+				code, n := order.Uint16(opts), int(order.Uint16(opts[2:]))
+				if code == pcapngOptEnd || len(opts) < 4+n {
+					break
+				}
+				if code == pcapngOptTsResol && n == 1 {
+					if v := opts[4]; v&0x80 == 0 {
+						link.unit = math.Pow(10, -float64(v))
+					} else {
+						link.unit = math.Pow(2, -float64(v&0x7f))
+					}
+				}
+				opts = opts[4+n+pad4(n):]
+			}
+			links = append(links, link)
+		case pcapngEnhancedPacket:
+			if len(body) < 20 {
+				return packets, ErrTruncatedCapture
+			}
+			i, n := int(order.Uint32(body)), int(order.Uint32(body[12:]))
+			if i >= len(links) {
+				return packets, ErrBadInterfaceID
+			}
+			if len(body) < 20+n {
+				return packets, ErrTruncatedCapture
+			}
+			ticks := uint64(order.Uint32(body[4:]))<<32 | uint64(order.Uint32(body[8:]))
+			when := ticksToTime(ticks, links[i].unit)
+			if ip := linkPayload(links[i].linkType, body[20:20+n]); ip != nil {
+				packets = append(packets, CapturedPacket{Time: when, Data: ip})
+			}
+		}
+	}
+	return packets, nil
+}
+
+func ticksToTime(ticks uint64, unit float64) time.Time {
+	perSecond := uint64(math.Round(1 / unit))
+	sec, frac := ticks/perSecond, ticks%perSecond
+	return time.Unix(int64(sec), int64(frac*uint64(time.Second)/perSecond))
+}
+
+// linkPayload strips the link layer header & returns the IPv4
+// packet it carries, or nil.
+func linkPayload(linkType int, frame []byte) []byte {
+	var ip []byte
+	switch linkType {
+	case linkTypeRaw, linkTypeIPv4:
+		ip = frame
+	case linkTypeNull:
+		// the address family is in host byte order; AF_INET is 2 everywhere
+		if len(frame) >= 4 && (binary.LittleEndian.Uint32(frame) == 2 || binary.BigEndian.Uint32(frame) == 2) {
+			ip = frame[4:]
+		}
+	case linkTypeEthernet:
+		for off := 12; len(frame) >= off+4; off += 4 {
+			etherType := binary.BigEndian.Uint16(frame[off:])
+			if etherType == 0x8100 || etherType == 0x88a8 {
+				// skip the VLAN tag
+				continue
+			}
+			if etherType == 0x0800 {
+				ip = frame[off+2:]
+			}
+			break
+		}
+	case linkTypeLinuxSLL:
+		if len(frame) >= 16 && binary.BigEndian.Uint16(frame[14:]) == 0x0800 {
+			ip = frame[16:]
+		}
+	case linkTypeSLL2:
+		if len(frame) >= 20 && binary.BigEndian.Uint16(frame) == 0x0800 {
+			ip = frame[20:]
+		}
+	}
+	if len(ip) < 20 || ip[0]>>4 != 4 {
+		return nil
+	}
+	return ip
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCaptureBadInterface(t *testing.T) {
+	var b bytes.Buffer
+	p, _ := NewPcapWriter(&b)
+	wm := NewEcho("hello", 1)
+	wb, _ := wm.Marshal(nil)
+	_ = p.WriteICMP(time.Unix(1500000000, 0), net.IPv4(192, 0, 2, 2), net.IPv4(192, 0, 2, 1), 64, wb, true, "")
+	capture := b.Bytes()
+	epb := 0
+	for rest := capture; binary.LittleEndian.Uint32(rest) != pcapngEnhancedPacket; {
+		length := binary.LittleEndian.Uint32(rest[4:])
+		epb += int(length)
+		rest = rest[length:]
+	}
+	binary.LittleEndian.PutUint32(capture[epb+8:], 5)
+	if _, err := ReadCapture(bytes.NewReader(capture)); err != ErrBadInterfaceID {
+		t.Errorf("expected %v ; got %v", ErrBadInterfaceID, err)
+	}
+}
+
+func TestReadPcapEthernet(t *testing.T) {
+	le := binary.LittleEndian
+	var b bytes.Buffer
+	for _, v := range []interface{}{uint32(0xa1b2c3d4), uint16(2), uint16(4), int32(0), uint32(0), uint32(65535), uint32(linkTypeEthernet)} {
+		_ = binary.Write(&b, le, v)
+	}
+	ip, _ := NewIPv4Header(net.IPv4(192, 0, 2, 1), nil, 8).Marshal()
+	frame := append(make([]byte, 12), 0x81, 0x00, 0, 5, 0x08, 0x00) // VLAN 5
+	frame = append(frame, ip...)
+	for _, v := range []interface{}{uint32(1500000000), uint32(250), uint32(len(frame)), uint32(len(frame))} {
+		_ = binary.Write(&b, le, v)
+	}
+	b.Write(frame)
+	frame[16], frame[17] = 0x86, 0xdd // IPv6 is skipped
+	for _, v := range []interface{}{uint32(1500000000), uint32(251), uint32(len(frame)), uint32(len(frame))} {
+		_ = binary.Write(&b, le, v)
+	}
+	b.Write(frame)
+
+	packets, err := ReadCapture(&b)
+	if err != nil || len(packets) != 1 {
+		t.Fatalf("expected 1 packet ; got %v %v", len(packets), err)
+	}
+	if !packets[0].Time.Equal(time.Unix(1500000000, 250000)) || !bytes.Equal(packets[0].Data, ip) {
+		t.Errorf("unexpected packet %v %x", packets[0].Time, packets[0].Data)
+	}
+	if _, err := ReadCapture(strings.NewReader("definitely not a capture file")); err != ErrNotCapture {
+		t.Errorf("expected %v ; got %v", ErrNotCapture, err)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/thirdparty"
+	"github.com/erriapo/stats"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tuning of ChangeDetector. Deviations are those of the baseline,
+// learnt from the first changeWarmup replies after every shift.
+const (
+	changeWarmup    = 20
+	changeSlack     = 0.5 // k: drift tolerated per reply, in deviations
+	changeThreshold = 8   // h: CUSUM signalling a shift, in deviations
+	changeClamp     = 4   // a single spike adds at most this much
+	minDeviation    = 0.05
+	relDeviation    = 0.02
+	spikeWindow     = 30
+	spikeMADs       = 6
+	minSpike        = 1.0
+)
+
+// ChangeDetector spots two kinds of latency anomalies in the
+// round trip times of the replies:
+//
+// A shift is a sustained change of level, e.g. a route change adding
+// 20 ms, found by a two sided CUSUM against the baseline. Each reply
+// is clamped to changeClamp deviations so that no lone spike is a shift.
+//
+// A spike is a single reply far above the median of the last
+// spikeWindow replies, by spikeMADs median absolute deviations, half
+// the median & minSpike ms at least. A reply is held back until the
+// next one arrives, as the first replies after a shift look alike.
+//
+// It is safe for concurrent use.
+type ChangeDetector struct {
+	lock     sync.Mutex
+	learning *stats.WelfordSink
+	mean     float64
+	sigma    float64
+	high     float64
+	low      float64
+	runs     [2][]float64 // [down, up] the latest replies since each CUSUM was last zero
+	recent   []float64
+	next     int
+	pending  *Event
+	baseline float64
+}
+
+// NewChangeDetector constructs a ChangeDetector learning its first baseline.
+func NewChangeDetector() *ChangeDetector {
+	return &ChangeDetector{learning: stats.NewSink()}
+}
+
+// Observe adds a reply, once per probe, see Repeats, & returns the
+// EventShift & EventSpike events it reveals, for the target & time
+// of e. Other events are ignored.
+func (d *ChangeDetector) Observe(e Event) []Event {
+	if e.Type != EventReply {
+		return nil
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	rtt := float64(e.RTT) / float64(time.Millisecond)
+
+	var found []Event
+	if spike := d.spike(e, rtt); spike != nil {
+		found = append(found, *spike)
+	}
+	if shift := d.shift(e, rtt); shift != nil {
+		found = append(found, *shift)
+	}
+	return found
+}
+
+// spike settles the reply held back & holds e back in turn.
+func (d *ChangeDetector) spike(e Event, rtt float64) *Event {
+	var found *Event
+	threshold, ok := d.spikeThreshold()
+	if d.pending != nil && ok && rtt <= threshold {
+		found = d.pending
+		found.Type = EventSpike
+		found.Baseline = int64(d.baseline * float64(time.Millisecond))
+	}
+	d.pending = nil
+	if ok && rtt > threshold {
+		held := e
+		d.pending, d.baseline = &held, d.median()
+	}
+	if len(d.recent) < spikeWindow {
+		d.recent = append(d.recent, rtt)
+	} else {
+		d.recent[d.next] = rtt
+		d.next = (d.next + 1) % spikeWindow
+	}
+	return found
+}
+
+func (d *ChangeDetector) median() float64 {
+	return medianOf(append([]float64(nil), d.recent...))
+}
+
+func medianOf(v []float64) float64 {
+	sort.Float64s(v)
+	n := len(v)
+	if n%2 == 1 {
+		return v[n/2]
+	}
+	return (v[n/2-1] + v[n/2]) / 2
+}
+
+func (d *ChangeDetector) spikeThreshold() (float64, bool) {
+	if len(d.recent) < spikeWindow/2 {
+		return 0, false
+	}
+	m := d.median()
+	deviations := make([]float64, len(d.recent))
+	for i, v := range d.recent {
+		deviations[i] = math.Abs(v - m)
+	}
+	// 1.4826 scales the MAD to a standard deviation
+	mad := 1.4826 * medianOf(deviations)
+	return m + math.Max(spikeMADs*mad, math.Max(m/2, minSpike)), true
+}
+
+// shift runs the CUSUM, or learns the baseline.
+func (d *ChangeDetector) shift(e Event, rtt float64) *Event {
+	if d.learning.Count() < changeWarmup {
+		_ = d.learning.Push(rtt)
+		if d.learning.Count() == changeWarmup {
+			d.mean = d.learning.Mean()
+			d.sigma = math.Max(d.learning.StandardDeviation(), math.Max(minDeviation, relDeviation*d.mean))
+		}
+		return nil
+	}
+	x := math.Max(d.mean-changeClamp*d.sigma, math.Min(d.mean+changeClamp*d.sigma, rtt))
+	d.high = math.Max(0, d.high+x-d.mean-changeSlack*d.sigma)
+	d.low = math.Max(0, d.low+d.mean-x-changeSlack*d.sigma)
+	for i, sum := range []float64{d.low, d.high} {
+		switch {
+		case sum == 0:
+			d.runs[i] = d.runs[i][:0]
+		case len(d.runs[i]) == spikeWindow:
+			d.runs[i] = append(d.runs[i][1:], rtt)
+		default:
+			d.runs[i] = append(d.runs[i], rtt)
+		}
+	}
+
+	up := d.high > changeThreshold*d.sigma
+	if !up && d.low <= changeThreshold*d.sigma {
+		return nil
+	}
+	found := e
+	found.Type = EventShift
+	found.Baseline = int64(d.mean * float64(time.Millisecond))
+	i := 0
+	if up {
+		i = 1
+	}
+	// the median shrugs off the replies of the run from before the shift
+	found.RTT = int64(medianOf(d.runs[i]) * float64(time.Millisecond))
+
+	// start over at the new level
+	d.learning = stats.NewSink()
+	d.high, d.low = 0, 0
+	d.runs = [2][]float64{}
+	d.recent, d.next, d.pending = d.recent[:0], 0, nil
+	return &found
+}
+
+// FormatChange renders an EventShift or EventSpike like the outage log, e.g.
+// "2017-07-14T02:40:00Z www.usenix.org rtt shifted up from 10.012 ms to 30.104 ms at icmp_seq=42".
+func FormatChange(e Event) string {
+	ms := func(ns int64) float64 {
+		return thirdparty.ToFixed(float64(ns)/float64(time.Millisecond), 3)
+	}
+	if e.Type == EventSpike {
+		return fmt.Sprintf("%s %s rtt spike of %v ms at icmp_seq=%d, baseline %v ms",
+			e.Time.Format(time.RFC3339), e.Target, ms(e.RTT), e.Seq, ms(e.Baseline))
+	}
+	direction := "down"
+	if e.RTT > e.Baseline {
+		direction = "up"
+	}
+	return fmt.Sprintf("%s %s rtt shifted %s from %v ms to %v ms at icmp_seq=%d",
+		e.Time.Format(time.RFC3339), e.Target, direction, ms(e.Baseline), ms(e.RTT), e.Seq)
+}
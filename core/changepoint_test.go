@@ -0,0 +1,49 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChangeDetector(t *testing.T) {
+	d := NewChangeDetector()
+	start := time.Unix(1500000000, 0).UTC()
+	var found []Event
+	// 10ms give or take 0.2ms, a lone 100ms spike, then a route change adding 20ms
+	for i := 0; i < 100; i++ {
+		rtt := 10 + 0.2*float64(i%3-1)
+		switch {
+		case i == 30:
+			rtt = 100
+		case i >= 60:
+			rtt += 20
+		}
+		e := Event{Type: EventReply, Target: "www.usenix.org", Seq: i + 1,
+			Time: start.Add(time.Duration(i) * time.Second), RTT: int64(rtt * float64(time.Millisecond))}
+		found = append(found, d.Observe(e)...)
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected a spike & a shift ; got %+v", found)
+	}
+	expected := "2017-07-14T02:40:30Z www.usenix.org rtt spike of 100 ms at icmp_seq=31, baseline 10 ms"
+	if found[0].Type != EventSpike || FormatChange(found[0]) != expected {
+		t.Errorf("expected <%v> ; got %+v", expected, found[0])
+	}
+	shift := found[1]
+	if shift.Type != EventShift || shift.Seq < 61 || shift.Seq > 65 ||
+		math.Abs(float64(shift.Baseline)/1e6-10) > 0.5 || math.Abs(float64(shift.RTT)/1e6-30) > 0.5 {
+		t.Errorf("expected a shift from 10ms to 30ms soon after icmp_seq=61 ; got %+v", shift)
+	}
+	if line := FormatChange(shift); !strings.Contains(line, " www.usenix.org rtt shifted up from ") {
+		t.Errorf("unexpected <%v>", line)
+	}
+	if d.Observe(Event{Type: EventTimeout, Seq: 101}) != nil {
+		t.Errorf("a timeout revealed a change")
+	}
+}
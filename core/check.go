@@ -0,0 +1,126 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Nagios plugin return codes, the exit status of --check.
+const (
+	CheckOK       = 0
+	CheckWarning  = 1
+	CheckCritical = 2
+	CheckUnknown  = 3
+)
+
+var checkStates = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+// ErrNoThresholds means --check was given without -w or -c.
+var ErrNoThresholds = errors.New("--check needs -w & -c thresholds")
+
+// ErrBadThreshold means -w or -c is not rtt,loss%, e.g. 100,20%.
+var ErrBadThreshold = errors.New("bad threshold, expected rtt,loss% e.g. 100,20%")
+
+// ErrThresholdOrder means a -w threshold is above its -c one.
+var ErrThresholdOrder = errors.New("-w thresholds must not be above -c thresholds")
+
+// Thresholds are a round trip time in milliseconds & a loss in percent.
+type Thresholds struct {
+	RTT  float64
+	Loss float64
+}
+
+// ParseThresholds parses check_ping style thresholds, e.g. "100,20%".
+func ParseThresholds(s string) (Thresholds, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 2 {
+		return Thresholds{}, ErrBadThreshold
+	}
+	rtt, err := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+	if err != nil || rtt < 0 {
+		return Thresholds{}, ErrBadThreshold
+	}
+	loss, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(fields[1]), "%"), 64)
+	if err != nil || loss < 0 || loss > 100 {
+		return Thresholds{}, ErrBadThreshold
+	}
+	return Thresholds{RTT: rtt, Loss: loss}, nil
+}
+
+// Check compares the statistics of a run with warning & critical
+// thresholds, like a Nagios or Icinga plugin.
+type Check struct {
+	Warning  Thresholds
+	Critical Thresholds
+}
+
+// NewCheck parses the -w & -c thresholds.
+func NewCheck(warning, critical string) (*Check, error) {
+	if warning == "" || critical == "" {
+		return nil, ErrNoThresholds
+	}
+	w, err := ParseThresholds(warning)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ParseThresholds(critical)
+	if err != nil {
+		return nil, err
+	}
+	if w.RTT > c.RTT || w.Loss > c.Loss {
+		return nil, ErrThresholdOrder
+	}
+	return &Check{Warning: w, Critical: c}, nil
+}
+
+// Evaluate returns CheckOK, CheckWarning or CheckCritical for the
+// average round trip time & the loss of s; CheckUnknown when
+// nothing was sent. A run without replies has no average to spare it.
+func (c *Check) Evaluate(s *Summary) int {
+	if s.Sent == 0 {
+		return CheckUnknown
+	}
+	l := s.ExactLoss()
+	switch {
+	case s.Received == 0 || s.Avg >= c.Critical.RTT || l >= c.Critical.Loss:
+		return CheckCritical
+	case s.Avg >= c.Warning.RTT || l >= c.Warning.Loss:
+		return CheckWarning
+	}
+	return CheckOK
+}
+
+// Render writes the plugin output line with its performance data, e.g.
+// "PING OK - www.usenix.org: rta 12.3 ms, lost 0%|rtt=12.3ms;100;250 pl=0%;20;60",
+// & returns the status.
+func (c *Check) Render(w io.Writer, s *Summary) int {
+	status := c.Evaluate(s)
+	if status == CheckUnknown {
+		fmt.Fprintf(w, "PING UNKNOWN - %s: no probe was sent\n", s.Target)
+		return status
+	}
+	rta, rtt := "n/a", "U"
+	if s.Received > 0 {
+		rta, rtt = formatMilli(s.Avg)+" ms", formatMilli(s.Avg)+"ms"
+	}
+	l := formatFloat(s.ExactLoss())
+	fmt.Fprintf(w, "PING %s - %s: rta %s, lost %s%%|rtt=%s;%s;%s pl=%s%%;%s;%s\n",
+		checkStates[status], s.Target, rta, l,
+		rtt, formatFloat(c.Warning.RTT), formatFloat(c.Critical.RTT),
+		l, formatFloat(c.Warning.Loss), formatFloat(c.Critical.Loss))
+	return status
+}
+
+// RenderUnknown writes the plugin output of a run that could
+// not be carried out & returns CheckUnknown.
+func RenderUnknown(w io.Writer, err interface{}) int {
+	fmt.Fprintf(w, "PING UNKNOWN - %v\n", err)
+	return CheckUnknown
+}
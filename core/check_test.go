@@ -0,0 +1,55 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	arg, err := ParseArgs([]string{"--check", "-w", "100,20%", "-c", "250,60%", "-c", "3", "localhost"})
+	if err != nil || !arg.Check || arg.Count != 3 || arg.Warning != "100,20%" || arg.Critical != "250,60%" {
+		t.Fatalf("unexpected %+v %v", arg, err)
+	}
+	for _, c := range []struct {
+		args     []string
+		expected error
+	}{
+		{[]string{"--check", "-w", "100,20%", "localhost"}, ErrNoThresholds},
+		{[]string{"-w", "100,20%", "-c", "250,60%", "localhost"}, ErrNoCheck},
+		{[]string{"--check", "-w", "100", "-c", "250,60%", "localhost"}, ErrBadThreshold},
+		{[]string{"--check", "-w", "100,120%", "-c", "250,60%", "localhost"}, ErrBadThreshold},
+		{[]string{"--check", "-w", "300,20%", "-c", "250,60%", "localhost"}, ErrThresholdOrder},
+		{[]string{"--check", "-w", "100,20%", "-c", "250,60%", "--format", "json", "localhost"}, ErrCheckFormat},
+	} {
+		if _, err := ParseArgs(c.args); err != c.expected {
+			t.Errorf("%v: expected %v ; got %v", c.args, c.expected, err)
+		}
+	}
+
+	check, _ := NewCheck("100,20%", "250,60%")
+	for _, c := range []struct {
+		summary  Summary
+		status   int
+		expected string
+	}{
+		{Summary{Target: "www.usenix.org", Sent: 5, Received: 5, Avg: 12.3456},
+			CheckOK, "PING OK - www.usenix.org: rta 12.346 ms, lost 0%|rtt=12.346ms;100;250 pl=0%;20;60\n"},
+		{Summary{Target: "www.usenix.org", Sent: 5, Received: 4, Avg: 12},
+			CheckWarning, "PING WARNING - www.usenix.org: rta 12.000 ms, lost 20%|rtt=12.000ms;100;250 pl=20%;20;60\n"},
+		{Summary{Target: "www.usenix.org", Sent: 5, Received: 5, Avg: 300},
+			CheckCritical, "PING CRITICAL - www.usenix.org: rta 300.000 ms, lost 0%|rtt=300.000ms;100;250 pl=0%;20;60\n"},
+		{Summary{Target: "www.usenix.org", Sent: 5},
+			CheckCritical, "PING CRITICAL - www.usenix.org: rta n/a, lost 100%|rtt=U;100;250 pl=100%;20;60\n"},
+		{Summary{Target: "www.usenix.org"},
+			CheckUnknown, "PING UNKNOWN - www.usenix.org: no probe was sent\n"},
+	} {
+		var b bytes.Buffer
+		if status := check.Render(&b, &c.summary); status != c.status || b.String() != c.expected {
+			t.Errorf("expected %d <%v> ; got %d <%v>", c.status, c.expected, status, b.String())
+		}
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"github.com/pelletier/go-toml"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConfigError locates what is wrong with a --config file.
+type ConfigError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+// ConfigTarget is a target of the --config file with its settings:
+// the defaults, overridden by those of its group & then its own.
+type ConfigTarget struct {
+	Host     string
+	Group    string
+	Labels   map[string]string
+	Settings *Module
+	// keys are the settings the file sets for the target
+	keys map[string]bool
+}
+
+// Config is a --config file, e.g.
+//
+//	[defaults]
+//	interval = "5s"
+//	timeout = "2s"
+//
+//	[groups.dns]
+//	targets = ["8.8.8.8", "1.1.1.1"]
+//	labels = { team = "infra" }
+//
+//	[targets."1.1.1.1"]
+//	size = 1400
+//
+// Settings are those of a Module: interval, count, size, timeout & protocol.
+// goping serve sends count echo requests to every target each interval;
+// the ping reads those of its target. Targets without a group get a
+// [targets."host"] table of their own. Command line flags take precedence
+// over the file.
+type Config struct {
+	Defaults *Module
+	Targets  []*ConfigTarget
+	defaults map[string]bool
+}
+
+// Target returns the settings of host, or nil when the file has none.
+func (c *Config) Target(host string) *ConfigTarget {
+	for _, t := range c.Targets {
+		if t.Host == host {
+			return t
+		}
+	}
+	return nil
+}
+
+// Settings returns those of host, the defaults when it has none.
+func (c *Config) Settings(host string) *Module {
+	if t := c.Target(host); t != nil {
+		return t.Settings
+	}
+	return c.Defaults
+}
+
+// Sets tells whether the file sets key for host, rather than
+// leaving it to the Module defaults.
+func (c *Config) Sets(host, key string) bool {
+	if t := c.Target(host); t != nil {
+		return t.keys[key]
+	}
+	return c.defaults[key]
+}
+
+// LoadConfig reads & validates the --config file at path.
+func LoadConfig(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseConfig(path, f)
+}
+
+// setting is a validated key = value of a table.
+type setting struct {
+	key, value string
+}
+
+// configTable is a [defaults], [groups.name] or [targets."host"] table.
+type configTable struct {
+	settings []setting
+	labels   map[string]string
+	targets  []string
+	hostLine int
+}
+
+// configParser validates the tables of the file.
+type configParser struct {
+	file       string
+	defaults   *configTable
+	groups     map[string]*configTable
+	groupOrder []string
+	targets    map[string]*configTable
+	hostOrder  []string
+}
+
+func (p *configParser) errorf(line int, format string, a ...interface{}) error {
+	return &ConfigError{File: p.file, Line: line, Err: fmt.Errorf(format, a...)}
+}
+
+// syntaxError is how go-toml reports where a file does not parse.
+var syntaxError = regexp.MustCompile(`^\((\d+), \d+\): (.*)$`)
+
+// ParseConfig reads & validates a --config file; name is used in errors.
+func ParseConfig(name string, r io.Reader) (*Config, error) {
+	p := &configParser{
+		file:     name,
+		defaults: &configTable{labels: make(map[string]string)},
+		groups:   make(map[string]*configTable),
+		targets:  make(map[string]*configTable),
+	}
+	tree, err := toml.LoadReader(r)
+	if err != nil {
+		if m := syntaxError.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return nil, p.errorf(line, "%s", m[2])
+		}
+		return nil, &ConfigError{File: name, Err: err}
+	}
+	for _, key := range inOrder(tree) {
+		table, ok := tree.GetPath([]string{key}).(*toml.Tree)
+		switch {
+		case !ok && isArrayOfTables(tree, key):
+			return nil, p.errorf(line(tree, key), "unknown table [[%s]], expected [defaults], [groups.name] or [targets.\"host\"]", key)
+		case !ok:
+			return nil, p.errorf(line(tree, key), "%s is outside of any table, e.g. [defaults]", key)
+		case key == "defaults":
+			err = p.table(table, p.defaults, false)
+		case key == "groups":
+			p.groupOrder, err = p.tables(table, p.groups, true)
+		case key == "targets":
+			p.hostOrder, err = p.tables(table, p.targets, false)
+		default:
+			err = p.errorf(line(tree, key), "unknown table [%s], expected [defaults], [groups.name] or [targets.\"host\"]", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p.resolve()
+}
+
+func isArrayOfTables(t *toml.Tree, key string) bool {
+	_, ok := t.GetPath([]string{key}).([]*toml.Tree)
+	return ok
+}
+
+// inOrder returns the keys of t in the order of the file.
+func inOrder(t *toml.Tree) []string {
+	keys := t.Keys()
+	sort.SliceStable(keys, func(i, j int) bool {
+		return line(t, keys[i]) < line(t, keys[j])
+	})
+	return keys
+}
+
+// line is where key of t is set, or where t starts. go-toml keeps
+// no positions inside inline tables; their errors point at the table.
+func line(t *toml.Tree, key string) int {
+	if pos := t.GetPositionPath([]string{key}); pos.Line > 0 {
+		return pos.Line
+	}
+	return t.Position().Line
+}
+
+// tables reads the [groups.name] or [targets."host"] tables of parent.
+func (p *configParser) tables(parent *toml.Tree, into map[string]*configTable, group bool) ([]string, error) {
+	names := inOrder(parent)
+	for _, name := range names {
+		t, ok := parent.GetPath([]string{name}).(*toml.Tree)
+		if !ok {
+			return nil, p.errorf(line(parent, name), "%s must be a table, e.g. [groups.name] or [targets.\"host\"]", name)
+		}
+		into[name] = &configTable{labels: make(map[string]string)}
+		if err := p.table(t, into[name], group); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// table validates the settings, labels & targets of t.
+func (p *configParser) table(t *toml.Tree, into *configTable, group bool) error {
+	for _, key := range inOrder(t) {
+		v, n := t.GetPath([]string{key}), line(t, key)
+		switch key {
+		case "labels":
+			labels, ok := v.(*toml.Tree)
+			if into == p.defaults || !ok {
+				return p.errorf(n, "labels must be a table of a group or target, e.g. labels = { team = \"infra\" }")
+			}
+			if err := p.labels(labels, into); err != nil {
+				return err
+			}
+		case "targets":
+			hosts, ok := v.([]interface{})
+			if !group || !ok {
+				return p.errorf(n, "targets must be an array of hosts in a [groups.name] table")
+			}
+			into.hostLine = n
+			for _, host := range hosts {
+				h, ok := host.(string)
+				if !ok || h == "" {
+					return p.errorf(n, "targets must be an array of hosts, e.g. [\"8.8.8.8\"]")
+				}
+				into.targets = append(into.targets, h)
+			}
+		default:
+			value, err := p.setting(key, v, n)
+			if err != nil {
+				return err
+			}
+			into.settings = append(into.settings, setting{key, value})
+		}
+	}
+	return nil
+}
+
+// labelName is what Prometheus accepts; target & group are goping's own.
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// labels reads a [groups.name.labels] table or an inline one.
+func (p *configParser) labels(t *toml.Tree, into *configTable) error {
+	for _, key := range inOrder(t) {
+		n := line(t, key)
+		if !labelName.MatchString(key) || strings.HasPrefix(key, "__") || key == "target" || key == "group" {
+			return p.errorf(n, "bad label name %q", key)
+		}
+		v, ok := t.GetPath([]string{key}).(string)
+		if !ok {
+			return p.errorf(n, "label %s must be a string", key)
+		}
+		into.labels[key] = v
+	}
+	return nil
+}
+
+// setting checks the type & the value of a Module setting.
+func (p *configParser) setting(key string, v interface{}, n int) (string, error) {
+	var value string
+	switch key {
+	case "count", "size":
+		i, ok := v.(int64)
+		if !ok {
+			return "", p.errorf(n, "%s must be an integer", key)
+		}
+		value = strconv.FormatInt(i, 10)
+	case "interval", "timeout":
+		s, ok := v.(string)
+		if !ok {
+			return "", p.errorf(n, "%s must be a string, e.g. %s = \"1s\"", key, key)
+		}
+		value = s
+	case "protocol":
+		s, ok := v.(string)
+		if !ok {
+			return "", p.errorf(n, "protocol must be a string, e.g. protocol = \"icmp6\"")
+		}
+		value = s
+	}
+	m := NewModule("")
+	if err := m.set(key, value); err != nil {
+		return "", p.errorf(n, "%s: %v", key, err)
+	}
+	return value, nil
+}
+
+// resolve layers the settings of every target.
+func (p *configParser) resolve() (*Config, error) {
+	c := &Config{Defaults: NewModule("defaults"), defaults: make(map[string]bool)}
+	apply(c.Defaults, p.defaults, c.defaults)
+
+	seen := make(map[string]string)
+	add := func(host, group string, layers ...*configTable) {
+		m := *c.Defaults
+		m.Name = host
+		labels := make(map[string]string)
+		keys := make(map[string]bool)
+		for k := range c.defaults {
+			keys[k] = true
+		}
+		for _, layer := range layers {
+			apply(&m, layer, keys)
+			for k, v := range layer.labels {
+				labels[k] = v
+			}
+		}
+		seen[host] = group
+		c.Targets = append(c.Targets, &ConfigTarget{Host: host, Group: group, Labels: labels, Settings: &m, keys: keys})
+	}
+	for _, name := range p.groupOrder {
+		g := p.groups[name]
+		for _, host := range g.targets {
+			if other, ok := seen[host]; ok {
+				return nil, p.errorf(g.hostLine, "target %q of group %s is already in group %s", host, name, other)
+			}
+			layers := []*configTable{g}
+			if t, ok := p.targets[host]; ok {
+				layers = append(layers, t)
+			}
+			add(host, name, layers...)
+		}
+	}
+	for _, host := range p.hostOrder {
+		if _, ok := seen[host]; !ok {
+			add(host, "", p.targets[host])
+		}
+	}
+	return c, nil
+}
+
+// apply sets the settings of t on m & notes their keys.
+func apply(m *Module, t *configTable, keys map[string]bool) {
+	for _, s := range t.settings {
+		// validated as they were read
+		_ = m.set(s.key, s.value)
+		keys[s.key] = true
+	}
+}
+
+// formatLabels renders labels as Prometheus label pairs, e.g. `,team="infra"`.
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, ",%s=\"%s\"", k, escapeLabel(labels[k]))
+	}
+	return b.String()
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"github.com/google/go-cmp/cmp"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfig = `# goping serve --config
+[defaults]
+interval = "5s"
+timeout = '2s'
+
+[groups.dns]
+targets = [
+	"8.8.8.8", # google
+	"1.1.1.1",
+]
+labels = { team = "infra", tier = "1" }
+count = 3
+
+[groups.web]
+targets = ["www.usenix.org"]
+
+[groups.web.labels]
+team = "web # not a comment"
+
+[targets."1.1.1.1"]
+size = 1_400
+
+[targets."192.0.2.1"]
+interval = "1m"
+labels = { site = "lab" }
+`
+
+func TestParseConfig(t *testing.T) {
+	c, err := ParseConfig("goping.toml", strings.NewReader(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults := Module{Name: "8.8.8.8", Count: 3, Interval: 5 * time.Second, Timeout: 2 * time.Second, Size: 56, Protocol: "icmp"}
+	keys := func(k ...string) map[string]bool {
+		m := map[string]bool{"interval": true, "timeout": true}
+		for _, key := range k {
+			m[key] = true
+		}
+		return m
+	}
+	expected := []*ConfigTarget{
+		{Host: "8.8.8.8", Group: "dns", Labels: map[string]string{"team": "infra", "tier": "1"}, Settings: &defaults, keys: keys("count")},
+		{Host: "1.1.1.1", Group: "dns", Labels: map[string]string{"team": "infra", "tier": "1"},
+			Settings: &Module{Name: "1.1.1.1", Count: 3, Interval: 5 * time.Second, Timeout: 2 * time.Second, Size: 1400, Protocol: "icmp"},
+			keys:     keys("count", "size")},
+		{Host: "www.usenix.org", Group: "web", Labels: map[string]string{"team": "web # not a comment"},
+			Settings: &Module{Name: "www.usenix.org", Count: 1, Interval: 5 * time.Second, Timeout: 2 * time.Second, Size: 56, Protocol: "icmp"},
+			keys:     keys()},
+		{Host: "192.0.2.1", Labels: map[string]string{"site": "lab"},
+			Settings: &Module{Name: "192.0.2.1", Count: 1, Interval: time.Minute, Timeout: 2 * time.Second, Size: 56, Protocol: "icmp"},
+			keys:     keys()},
+	}
+	if !cmp.Equal(c.Targets, expected, cmp.AllowUnexported(ConfigTarget{})) {
+		t.Errorf("unexpected targets: %v", cmp.Diff(expected, c.Targets, cmp.AllowUnexported(ConfigTarget{})))
+	}
+	if c.Settings("192.0.2.99") != c.Defaults || c.Defaults.Count != 1 {
+		t.Errorf("expected the defaults for a target not in the file ; got %+v", c.Settings("192.0.2.99"))
+	}
+	if !c.Sets("1.1.1.1", "size") || c.Sets("8.8.8.8", "size") || !c.Sets("192.0.2.99", "timeout") || c.Sets("192.0.2.99", "count") {
+		t.Errorf("unexpected keys set by the file")
+	}
+
+	c, err = ParseConfig("goping.toml", strings.NewReader("[targets.\"2001:db8::1\"]\nprotocol = \"icmp6\"\n"))
+	if err != nil || c.Settings("2001:db8::1").Protocol != "icmp6" || c.Defaults.Protocol != "icmp" {
+		t.Errorf("expected the protocol of the target ; got %+v %v", c, err)
+	}
+
+	for _, bad := range []struct{ config, expected string }{
+		{"[defaults]\ninterval = 5", "x.toml:2: interval must be a string, e.g. interval = \"1s\""},
+		{"[defaults]\n\ncount = 0", "x.toml:3: count: " + ErrBadCount.Error()},
+		{"[defaults]\nintervl = \"1s\"", "x.toml:2: intervl: unknown setting \"intervl\""},
+		{"[defaults]\ninterval = \"0s\"", "x.toml:2: interval: " + ErrBadInterval.Error()},
+		{"[defaults]\nsize = 1\nsize = 2", "x.toml:3: The following key was defined twice: defaults.size"},
+		{"count = 1", "x.toml:1: count is outside of any table, e.g. [defaults]"},
+		{"[server]", "x.toml:1: unknown table [server], expected [defaults], [groups.name] or [targets.\"host\"]"},
+		{"[defaults]\n[defaults]", "x.toml:2: duplicated tables"},
+		{"[groups.a]\ntargets = [\"h\"]\n[groups.b]\ntargets = [\"h\"]", "x.toml:4: target \"h\" of group b is already in group a"},
+		{"[groups.a]\ntargets = [\"h\",\n 3]", "x.toml:2: targets must be an array of hosts, e.g. [\"8.8.8.8\"]"},
+		{"[groups.a]\nlabels = { target = \"x\" }", "x.toml:1: bad label name \"target\""},
+		{"[targets.h]\ntargets = [\"x\"]", "x.toml:2: targets must be an array of hosts in a [groups.name] table"},
+		{"[defaults]\ntimeout = \"1s", "x.toml:2: unclosed string"},
+		{"[defaults]\ncount = 1.5", "x.toml:2: count must be an integer"},
+		{"[defaults]\nprotocol = 6", "x.toml:2: protocol must be a string, e.g. protocol = \"icmp6\""},
+		{"[defaults]\nprotocol = \"udp\"", "x.toml:2: protocol: " + ErrBadProtocol.Error()},
+		{"[defaults]\ninterval", "x.toml:2: was expecting token =, but got EOF instead"},
+		{"[groups.a]\ntargets = [\"h\"]\n\n[groups.a.labels]\nteam = 1", "x.toml:5: label team must be a string"},
+		{"[[targets]]\nhost = \"h\"", "x.toml:1: unknown table [[targets]], expected [defaults], [groups.name] or [targets.\"host\"]"},
+		{"[targets]\nh = 1", "x.toml:2: h must be a table, e.g. [groups.name] or [targets.\"host\"]"},
+	} {
+		_, err := ParseConfig("x.toml", strings.NewReader(bad.config))
+		if err == nil || err.Error() != bad.expected {
+			t.Errorf("%q: expected <%v> ; got <%v>", bad.config, bad.expected, err)
+		}
+	}
+
+	f, err := ioutil.TempFile("", "goping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(testConfig)
+	f.Close()
+	// flags take precedence over the file
+	arg, err := ParseServeArgs([]string{"--config", f.Name(), "-W", "3s", "192.0.2.7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(arg.Probes) != 5 || arg.Probes[3].Settings.Interval != time.Minute || arg.Probes[3].Settings.Timeout != 3*time.Second ||
+		arg.Probes[4].Host != "192.0.2.7" || arg.Probes[4].Settings.Interval != 5*time.Second {
+		t.Errorf("unexpected probes %+v", arg.Probes)
+	}
+	arg2, err := ParseArgs([]string{"--config", f.Name(), "1.1.1.1"})
+	if err != nil || arg2.Count != 3 || arg2.Interval != 5*time.Second || arg2.Timeout != 2*time.Second || arg2.Size != 1400 {
+		t.Errorf("expected the settings of the file ; got %+v %v", arg2, err)
+	}
+	if arg2, err = ParseArgs([]string{"--config", f.Name(), "-c", "7", "1.1.1.1"}); err != nil || arg2.Count != 7 {
+		t.Errorf("expected the count of -c ; got %+v %v", arg2, err)
+	}
+	// the defaults of the ping stay when the file has none
+	if arg2, err = ParseArgs([]string{"--config", f.Name(), "192.0.2.1"}); err != nil || arg2.Count != 5 || arg2.Interval != time.Minute || arg2.Size != 56 {
+		t.Errorf("expected -c 5 & the interval of the file ; got %+v %v", arg2, err)
+	}
+	g, err := ioutil.TempFile("", "goping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(g.Name())
+	g.WriteString("[defaults]\nprotocol = \"icmp6\"\n")
+	g.Close()
+	if _, err := ParseArgs([]string{"--config", g.Name(), "1.1.1.1"}); err != ErrConfigProtocol {
+		t.Errorf("expected %v ; got %v", ErrConfigProtocol, err)
+	}
+
+	m := NewMetrics()
+	m.SetLabels("1.1.1.1", "dns", map[string]string{"tier": "1", "team": "infra"})
+	var b bytes.Buffer
+	m.Render(&b)
+	if line := `goping_probes_sent_total{target="1.1.1.1",group="dns",team="infra",tier="1"} 0`; !strings.Contains(b.String(), line+"\n") {
+		t.Errorf("missing <%v> in\n%v", line, b.String())
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// csvHeader must only ever grow at the end;
+// spreadsheets & pandas scripts index the columns.
+var csvHeader = []string{"timestamp", "target", "seq", "rtt_ms", "ttl", "status", "error_code", "peer", "fqdn"}
+
+var csvSummaryHeader = []string{"target", "sent", "received", "errors", "loss_percent",
+	"rtt_min_ms", "rtt_avg_ms", "rtt_max_ms", "rtt_mdev_ms"}
+
+func formatMilli(f float64) string {
+	return strconv.FormatFloat(f, 'f', 3, 64)
+}
+
+func csvRecord(e Event) []string {
+	var rtt, ttl, code string
+	if e.Type == EventReply {
+		rtt = formatMilli(float64(e.RTT) / float64(time.Millisecond))
+	}
+	if e.TTL > 0 {
+		ttl = strconv.Itoa(e.TTL)
+	}
+	if e.ICMPType != 0 {
+		code = fmt.Sprintf("%d/%d", e.ICMPType, e.ICMPCode)
+	}
+	return []string{
+		e.Time.Format(time.RFC3339Nano),
+		e.Target,
+		strconv.Itoa(e.Seq),
+		rtt,
+		ttl,
+		e.Type,
+		code,
+		e.Peer,
+		e.FQDN,
+	}
+}
+
+func csvSummaryRecord(s *Summary) []string {
+	return []string{
+		s.Target,
+		strconv.FormatUint(s.Sent, 10),
+		strconv.FormatUint(s.Received, 10),
+		strconv.FormatUint(s.Errors, 10),
+		strconv.FormatUint(uint64(s.Loss), 10),
+		formatMilli(s.Min),
+		formatMilli(s.Avg),
+		formatMilli(s.Max),
+		formatMilli(s.Mdev),
+	}
+}
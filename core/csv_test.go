@@ -0,0 +1,39 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestOutputCSV(t *testing.T) {
+	var b, summary bytes.Buffer
+	o, err := NewOutput(&b, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.SetSummary(&summary)
+	when := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	o.Emit(Event{Type: EventSent, Time: when, Target: "192.0.2.1", Seq: 1})
+	o.Emit(Event{Type: EventReply, Time: when, Target: "192.0.2.1", Seq: 1, TTL: 57,
+		RTT: 1234567, Peer: "192.0.2.1", FQDN: TryConvertPunycode("xn--bdk.ws") + ",x"})
+	o.Emit(Event{Type: EventError, Time: when, Target: "192.0.2.1", Seq: 2,
+		Peer: "198.51.100.1", ICMPType: 11, ICMPCode: 0})
+	o.Finish(&Summary{Target: "192.0.2.1", Sent: 2, Received: 1, Loss: 50, Min: 1.234567})
+
+	expected := "timestamp,target,seq,rtt_ms,ttl,status,error_code,peer,fqdn\n" +
+		"2018-01-02T03:04:05Z,192.0.2.1,1,1.235,57,reply,,192.0.2.1,\"ツ.ws,x\"\n" +
+		"2018-01-02T03:04:05Z,192.0.2.1,2,,,error,11/0,198.51.100.1,\n"
+	if b.String() != expected {
+		t.Errorf("expected <%v> ; got <%v>", expected, b.String())
+	}
+	expected = "target,sent,received,errors,loss_percent,rtt_min_ms,rtt_avg_ms,rtt_max_ms,rtt_mdev_ms\n" +
+		"192.0.2.1,2,1,0,50,1.235,0.000,0.000,0.000\n"
+	if summary.String() != expected {
+		t.Errorf("expected <%v> ; got <%v>", expected, summary.String())
+	}
+}
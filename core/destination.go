@@ -0,0 +1,149 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenDestination opens where a collector output is written.
+// "-" is stdout, "udp://host:port" sends one datagram per write,
+// "http://" & "https://" POST the writes in batches & anything else is a file.
+func OpenDestination(dest string) (io.WriteCloser, error) {
+	switch {
+	case dest == "-":
+		return nopCloser{os.Stdout}, nil
+	case strings.HasPrefix(dest, "udp://"):
+		return net.Dial("udp", strings.TrimPrefix(dest, "udp://"))
+	case strings.HasPrefix(dest, "http://"), strings.HasPrefix(dest, "https://"):
+		return newHTTPWriter(dest, "text/plain; charset=utf-8", true), nil
+	}
+	return os.Create(dest)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// How httpWriter batches: writes are queued & POSTed together every
+// httpFlushEvery, at most httpMaxPending of them are kept for a slow
+// or unreachable collector.
+const (
+	httpFlushEvery = time.Second
+	httpMaxPending = 10000
+)
+
+// httpWriter POSTs writes in the background, e.g. to InfluxDB's /write
+// endpoint, so a slow collector does not hold up the probes. Writes
+// are joined into one request per flush when join is set, as lines of
+// the InfluxDB line protocol are; otherwise each is a request of its own.
+type httpWriter struct {
+	url         string
+	contentType string
+	client      *http.Client
+	join        bool
+
+	lock    sync.Mutex
+	pending [][]byte
+	dropped int
+	failing bool
+	flushed chan chan struct{}
+}
+
+func newHTTPWriter(url, contentType string, join bool) *httpWriter {
+	h := &httpWriter{url: url, contentType: contentType, join: join,
+		client: &http.Client{Timeout: 5 * time.Second}, flushed: make(chan chan struct{})}
+	go h.run()
+	return h
+}
+
+// Write queues a copy of b; it never blocks on the network.
+func (h *httpWriter) Write(b []byte) (int, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if len(h.pending) == httpMaxPending {
+		h.pending = h.pending[1:]
+		h.dropped++
+	}
+	h.pending = append(h.pending, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func (h *httpWriter) run() {
+	tick := time.NewTicker(httpFlushEvery)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			h.send()
+		case done := <-h.flushed:
+			h.send()
+			close(done)
+		}
+	}
+}
+
+// send POSTs what is queued. The first failure is logged, & again
+// after the collector recovered.
+func (h *httpWriter) send() {
+	h.lock.Lock()
+	pending, dropped := h.pending, h.dropped
+	h.pending, h.dropped = nil, 0
+	h.lock.Unlock()
+
+	if dropped > 0 {
+		log.Printf("%v: dropped %d writes the collector was too slow for", h.url, dropped)
+	}
+	if h.join && len(pending) > 0 {
+		pending = [][]byte{bytes.Join(pending, nil)}
+	}
+	for _, b := range pending {
+		err := h.post(b)
+		if err != nil && !h.failing {
+			log.Print(err)
+		}
+		h.failing = err != nil
+	}
+}
+
+func (h *httpWriter) post(b []byte) error {
+	resp, err := h.client.Post(h.url, h.contentType, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%v: %v", h.url, resp.Status)
+	}
+	return nil
+}
+
+// Flush POSTs what is queued & waits for it.
+func (h *httpWriter) Flush() error {
+	done := make(chan struct{})
+	h.flushed <- done
+	<-done
+	return nil
+}
+
+func (h *httpWriter) Close() error {
+	return h.Flush()
+}
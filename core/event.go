@@ -0,0 +1,330 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/erriapo/stats"
+	"io"
+	"sync"
+	"time"
+)
+
+// SchemaVersion is bumped whenever Event or Summary
+// change in a way that breaks existing consumers.
+const SchemaVersion = 1
+
+// Event types
+const (
+	EventSent    = "sent"
+	EventReply   = "reply"
+	EventTimeout = "timeout"
+	EventError   = "error"
+	EventSummary = "summary"
+	EventShift   = "shift"
+	EventSpike   = "spike"
+)
+
+// Output formats
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+	FormatInflux = "influx"
+	FormatStatsD = "statsd"
+)
+
+// ErrBadFormat means --format was not one of the supported formats.
+var ErrBadFormat = errors.New("unknown output format")
+
+// Event is one line of --format ndjson output.
+// Fields that do not apply to the event type are omitted;
+// a missing icmp_code means code 0. Shift & spike events
+// annotate the reply of Seq: rtt_ns is the new level of a
+// shift or the spike, baseline_ns the level before.
+type Event struct {
+	Version  int       `json:"version"`
+	Type     string    `json:"type"`
+	Time     time.Time `json:"timestamp"`
+	Target   string    `json:"target"`
+	Seq      int       `json:"seq,omitempty"`
+	TTL      int       `json:"ttl,omitempty"`
+	RTT      int64     `json:"rtt_ns,omitempty"`
+	Peer     string    `json:"peer,omitempty"`
+	FQDN     string    `json:"fqdn,omitempty"`
+	MAC      string    `json:"mac,omitempty"`
+	ICMPType int       `json:"icmp_type,omitempty"`
+	ICMPCode int       `json:"icmp_code,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Status   string    `json:"interface_status,omitempty"`
+	Baseline int64     `json:"baseline_ns,omitempty"`
+	Summary  *Summary  `json:"summary,omitempty"`
+}
+
+// Summary holds the final statistics of a run.
+// Round trip times are in milliseconds.
+type Summary struct {
+	Version      int           `json:"version"`
+	Target       string        `json:"target"`
+	Sent         uint64        `json:"sent"`
+	Received     uint64        `json:"received"`
+	Errors       uint64        `json:"errors"`
+	Loss         uint32        `json:"loss_percent"`
+	Min          float64       `json:"rtt_min_ms"`
+	Avg          float64       `json:"rtt_avg_ms"`
+	Max          float64       `json:"rtt_max_ms"`
+	Mdev         float64       `json:"rtt_mdev_ms"`
+	P50          float64       `json:"rtt_p50_ms"`
+	P90          float64       `json:"rtt_p90_ms"`
+	P95          float64       `json:"rtt_p95_ms"`
+	P99          float64       `json:"rtt_p99_ms"`
+	P999         float64       `json:"rtt_p99_9_ms"`
+	Jitter       float64       `json:"jitter_ms"`
+	IPDVMean     float64       `json:"ipdv_mean_ms"`
+	IPDVMax      float64       `json:"ipdv_max_ms"`
+	RFactor      float64       `json:"r_factor"`
+	MOS          float64       `json:"mos"`
+	Bursts       *LossBursts   `json:"loss_bursts,omitempty"`
+	Outages      []Outage      `json:"outages,omitempty"`
+	Availability float64       `json:"availability_percent"`
+	Windows      *WindowReport `json:"windows,omitempty"`
+	Events       []Event       `json:"events,omitempty"`
+}
+
+// ExactLoss is the loss percentage; Loss is rounded down. Duplicates
+// & the replies of broadcast pings make up for no loss.
+func (s *Summary) ExactLoss() float64 {
+	if s.Received >= s.Sent {
+		return 0
+	}
+	return 100 * float64(s.Sent-s.Received) / float64(s.Sent)
+}
+
+// NewSummary gathers the statistics kept by c, sink & q.
+// The Welford sink provides min/avg/max/mdev, q the percentiles.
+func NewSummary(target string, c *Counter, sink *stats.WelfordSink, q *Quantiles) *Summary {
+	c.calculateLoss()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	s := &Summary{
+		Version:  SchemaVersion,
+		Target:   target,
+		Sent:     c.Sent,
+		Received: c.Recvd,
+		Errors:   c.Errors,
+		Loss:     c.Loss,
+	}
+	if sink.Count() > 0 {
+		s.Min, s.Avg, s.Max, s.Mdev = sink.Min(), sink.Mean(), sink.Max(), sink.StandardDeviation()
+	}
+	if q != nil && q.Count() > 0 {
+		s.P50, s.P90, s.P95, s.P99, s.P999 = q.Percentile(50), q.Percentile(90), q.Percentile(95), q.Percentile(99), q.Percentile(99.9)
+	}
+	return s
+}
+
+// isOutcome tells replies, timeouts & errors from other events.
+func isOutcome(typ string) bool {
+	return typ == EventReply || typ == EventTimeout || typ == EventError
+}
+
+// Lost tells whether e settles a probe & whether the probe was lost:
+// replies are received, timeouts & ICMP errors lost.
+func Lost(e Event) (lost, ok bool) {
+	return e.Type != EventReply, isOutcome(e.Type)
+}
+
+// Repeats lets through one outcome per probe. Duplicate replies, e.g.
+// from every host of a broadcast, carry the sequence number of the
+// outcome before them. It is safe for concurrent use.
+type Repeats struct {
+	lock    sync.Mutex
+	have    bool
+	lastSeq int
+}
+
+// Repeated tells whether e repeats the previous outcome; other events never do.
+func (r *Repeats) Repeated(e Event) bool {
+	if !isOutcome(e.Type) {
+		return false
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.have && e.Seq == r.lastSeq {
+		return true
+	}
+	r.have, r.lastSeq = true, e.Seq
+	return false
+}
+
+// Output writes events in a machine readable format.
+// With FormatJSON the events are held back & written
+// inside the summary document.
+type Output struct {
+	format  string
+	w       io.Writer
+	enc     *json.Encoder
+	csv     *csv.Writer
+	summary io.Writer
+	tags    Tags
+	otlp    *otlpState
+	lock    sync.Mutex
+	events  []Event
+}
+
+// NewOutput returns nil for FormatText.
+func NewOutput(w io.Writer, format string) (*Output, error) {
+	switch format {
+	case FormatText:
+		return nil, nil
+	case FormatJSON, FormatNDJSON:
+		return &Output{format: format, enc: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		o := &Output{format: format, csv: csv.NewWriter(w)}
+		_ = o.csv.Write(csvHeader)
+		o.csv.Flush()
+		return o, nil
+	case FormatInflux, FormatStatsD:
+		return &Output{format: format, w: w}, nil
+	}
+	return nil, ErrBadFormat
+}
+
+// SetSummary sends the FormatCSV summary to w.
+// Without it a CSV Output writes no summary.
+func (o *Output) SetSummary(w io.Writer) {
+	o.summary = w
+}
+
+// SetTags labels every FormatInflux, FormatStatsD & OTLP measurement.
+func (o *Output) SetTags(t Tags) {
+	o.tags = t
+}
+
+// Emit writes or buffers one event. A nil Output discards it.
+func (o *Output) Emit(e Event) {
+	if o == nil {
+		return
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	e.Version = SchemaVersion
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	switch o.format {
+	case FormatJSON:
+		o.events = append(o.events, e)
+	case FormatCSV:
+		// one row per probe outcome
+		if isOutcome(e.Type) {
+			_ = o.csv.Write(csvRecord(e))
+			o.csv.Flush()
+		}
+	case FormatInflux:
+		if isOutcome(e.Type) {
+			_, _ = io.WriteString(o.w, influxEvent(o.tags, e))
+		}
+	case FormatStatsD:
+		if line := statsdEvent(o.tags, e); line != "" {
+			_, _ = io.WriteString(o.w, line)
+		}
+	case formatOTLP:
+		o.otlp.observe(e)
+	default:
+		_ = o.enc.Encode(e)
+	}
+}
+
+// Report writes interim statistics. Only the streaming
+// formats, ndjson, influx, statsd & OTLP, have a use for them.
+func (o *Output) Report(s *Summary) {
+	if o == nil {
+		return
+	}
+	switch o.format {
+	case FormatNDJSON:
+		o.Emit(Event{Type: EventSummary, Target: s.Target, Summary: s})
+	case FormatInflux:
+		o.lock.Lock()
+		defer o.lock.Unlock()
+		_, _ = io.WriteString(o.w, influxSummary(o.tags, s, time.Now()))
+	case FormatStatsD:
+		o.lock.Lock()
+		defer o.lock.Unlock()
+		_, _ = io.WriteString(o.w, statsdSummary(o.tags, s))
+	case formatOTLP:
+		o.lock.Lock()
+		defer o.lock.Unlock()
+		if b, err := o.otlp.marshal(o.tags, time.Now()); err == nil {
+			_, _ = o.w.Write(b)
+		}
+	}
+}
+
+// flusher is a destination writing in the background, e.g. over HTTP.
+type flusher interface {
+	Flush() error
+}
+
+// Finish writes the summary & waits for it to be sent.
+func (o *Output) Finish(s *Summary) {
+	if o == nil {
+		return
+	}
+	switch o.format {
+	case FormatNDJSON, FormatInflux, FormatStatsD, formatOTLP:
+		o.Report(s)
+		if f, ok := o.w.(flusher); ok {
+			_ = f.Flush()
+		}
+		return
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.format == FormatCSV {
+		if o.summary != nil {
+			w := csv.NewWriter(o.summary)
+			_ = w.Write(csvSummaryHeader)
+			_ = w.Write(csvSummaryRecord(s))
+			w.Flush()
+		}
+		return
+	}
+	doc := *s
+	doc.Events = o.events
+	_ = o.enc.Encode(&doc)
+}
+
+// Outputs fans events out to every Output.
+type Outputs []*Output
+
+// Emit hands e to every Output.
+func (outputs Outputs) Emit(e Event) {
+	for _, o := range outputs {
+		o.Emit(e)
+	}
+}
+
+// Report hands interim statistics to every Output.
+func (outputs Outputs) Report(s *Summary) {
+	for _, o := range outputs {
+		o.Report(s)
+	}
+}
+
+// Finish hands s to every Output.
+func (outputs Outputs) Finish(s *Summary) {
+	for _, o := range outputs {
+		o.Finish(s)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/erriapo/stats"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutputNDJSON(t *testing.T) {
+	var b bytes.Buffer
+	o, err := NewOutput(&b, FormatNDJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	when := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	o.Emit(Event{Type: EventReply, Time: when, Target: "127.0.0.1", Seq: 1, TTL: 64, RTT: 1500000, Peer: "127.0.0.1"})
+	counter := NewCounter()
+	counter.OnSent()
+	counter.OnReception()
+	sink := stats.NewSink()
+	_ = sink.Push(1.5)
+	s := NewSummary("127.0.0.1", counter, sink, nil)
+	o.Finish(s)
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines ; got %v", lines)
+	}
+	expected := `{"version":1,"type":"reply","timestamp":"2018-01-02T03:04:05Z","target":"127.0.0.1","seq":1,"ttl":64,"rtt_ns":1500000,"peer":"127.0.0.1"}`
+	if lines[0] != expected {
+		t.Errorf("expected <%v> ; got <%v>", expected, lines[0])
+	}
+	var last Event
+	if err := json.Unmarshal([]byte(lines[1]), &last); err != nil {
+		t.Fatal(err)
+	}
+	if last.Type != EventSummary || last.Summary.Received != 1 || last.Summary.Avg != 1.5 {
+		t.Errorf("unexpected summary %+v", last.Summary)
+	}
+}
+
+func TestOutputJSON(t *testing.T) {
+	var b bytes.Buffer
+	o, _ := NewOutput(&b, FormatJSON)
+	o.Emit(Event{Type: EventTimeout, Target: "127.0.0.1", Seq: 1})
+	if b.Len() != 0 {
+		t.Errorf("events should wait for the summary ; got %v", b.String())
+	}
+	o.Finish(NewSummary("127.0.0.1", NewCounter(), stats.NewSink(), nil))
+	var s Summary
+	if err := json.Unmarshal(b.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != SchemaVersion || len(s.Events) != 1 || s.Events[0].Type != EventTimeout {
+		t.Errorf("unexpected document %v", b.String())
+	}
+	if _, err := NewOutput(&b, "xml"); err != ErrBadFormat {
+		t.Errorf("expected %v ; got %v", ErrBadFormat, err)
+	}
+
+	b.Reset()
+	sink, q := stats.NewSink(), NewQuantiles()
+	for _, rtt := range []float64{1, 2, 3, 4, 100} {
+		_ = sink.Push(rtt)
+		q.Push(rtt)
+	}
+	o, _ = NewOutput(&b, FormatJSON)
+	o.Finish(NewSummary("127.0.0.1", NewCounter(), sink, q))
+	s = Summary{}
+	if err := json.Unmarshal(b.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(s.P50-3)/3 > quantileAccuracy || s.P999 != 100 || !strings.Contains(b.String(), `"rtt_p99_9_ms":100`) {
+		t.Errorf("unexpected percentiles in %v", b.String())
+	}
+}
+
+func TestRepeats(t *testing.T) {
+	var r Repeats
+	for _, c := range []struct {
+		e        Event
+		expected bool
+	}{
+		{Event{Type: EventSent, Seq: 1}, false},
+		{Event{Type: EventReply, Seq: 1}, false},
+		{Event{Type: EventReply, Seq: 1}, true},
+		{Event{Type: EventSent, Seq: 2}, false},
+		{Event{Type: EventTimeout, Seq: 2}, false},
+		{Event{Type: EventError, Seq: 3}, false},
+		{Event{Type: EventReply, Seq: 3}, true},
+	} {
+		if got := r.Repeated(c.e); got != c.expected {
+			t.Errorf("%+v: expected %v ; got %v", c.e, c.expected, got)
+		}
+	}
+	if lost, ok := Lost(Event{Type: EventError}); !lost || !ok {
+		t.Errorf("expected an ICMP error to be a loss")
+	}
+	if _, ok := Lost(Event{Type: EventSent}); ok {
+		t.Errorf("expected no outcome for a sent probe")
+	}
+}
+
+func TestMaxLoss(t *testing.T) {
+	for _, c := range []struct {
+		sent, received uint64
+		expected       float64
+	}{
+		{5, 5, 0},
+		{5, 4, 20},
+		{3, 2, 100.0 / 3},
+		{5, 0, 100},
+		{0, 0, 0},
+		{1, 3, 0}, // broadcast
+	} {
+		s := &Summary{Sent: c.sent, Received: c.received}
+		if l := s.ExactLoss(); l != c.expected {
+			t.Errorf("%d/%d: expected %v ; got %v", c.received, c.sent, c.expected, l)
+		}
+	}
+	if arg, err := ParseArgs([]string{"--max-loss", "20", "localhost"}); err != nil || arg.MaxLoss != 20 {
+		t.Errorf("expected --max-loss 20 ; got %v %v", arg, err)
+	}
+	if _, err := ParseArgs([]string{"--max-loss", "101", "localhost"}); err != ErrBadMaxLoss {
+		t.Errorf("expected %v ; got %v", ErrBadMaxLoss, err)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"golang.org/x/net/icmp"
+	"strings"
+)
+
+// Interface roles carried in the top two bits of the
+// Interface Information Object C-Type (RFC 5837 section 4.1)
+var interfaceRoles = []string{
+	"incoming interface",
+	"sub-IP component",
+	"outgoing interface",
+	"next-hop",
+}
+
+// Extensions returns the RFC 4884 extension objects attached
+// to an ICMP error message. It returns nil for other messages.
+func Extensions(m *icmp.Message) []icmp.Extension {
+	if m == nil {
+		return nil
+	}
+	switch body := m.Body.(type) {
+	case *icmp.TimeExceeded:
+		return body.Extensions
+	case *icmp.DstUnreach:
+		return body.Extensions
+	case *icmp.ParamProb:
+		return body.Extensions
+	}
+	return nil
+}
+
+// DescribeExtension renders an extension object on a single line.
+func DescribeExtension(ext icmp.Extension) string {
+	switch e := ext.(type) {
+	case *icmp.MPLSLabelStack:
+		labels := make([]string, 0, len(e.Labels))
+		for _, l := range e.Labels {
+			labels = append(labels, fmt.Sprintf("%d/tc=%d/s=%v/ttl=%d", l.Label, l.TC, l.S, l.TTL))
+		}
+		return "MPLS labels: " + strings.Join(labels, " ")
+	case *icmp.InterfaceInfo:
+		var bits []string
+		if e.Interface != nil {
+			if e.Interface.Name != "" {
+				bits = append(bits, "name="+e.Interface.Name)
+			}
+			if e.Interface.Index > 0 {
+				bits = append(bits, fmt.Sprintf("index=%d", e.Interface.Index))
+			}
+			if e.Interface.MTU > 0 {
+				bits = append(bits, fmt.Sprintf("mtu=%d", e.Interface.MTU))
+			}
+		}
+		if e.Addr != nil {
+			bits = append(bits, "ip="+e.Addr.String())
+		}
+		return interfaceRoles[(e.Type>>6)&0x3] + ": " + strings.Join(bits, " ")
+	case *icmp.InterfaceIdent:
+		return fmt.Sprintf("interface ident: class=%d type=%d", e.Class, e.Type)
+	case *icmp.RawExtension:
+		return fmt.Sprintf("extension: %d bytes", len(e.Data))
+	}
+	return fmt.Sprintf("extension: %T", ext)
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"net"
+	"testing"
+)
+
+func TestDescribeExtensions(t *testing.T) {
+	wm := icmp.Message{Type: ipv4.ICMPTypeTimeExceeded, Code: 0,
+		Body: &icmp.TimeExceeded{
+			Data: make([]byte, 128),
+			Extensions: []icmp.Extension{
+				&icmp.MPLSLabelStack{Class: 1, Type: 1,
+					Labels: []icmp.MPLSLabel{{Label: 16014, TC: 0x4, S: true, TTL: 255}}},
+				&icmp.InterfaceInfo{Class: 2, Type: 0x0f,
+					Interface: &net.Interface{Index: 15, Name: "en101", MTU: 8192},
+					Addr:      &net.IPAddr{IP: net.IPv4(192, 168, 0, 1).To4()}},
+			},
+		},
+	}
+	wb, err := wm.Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm, err := icmp.ParseMessage(1, wb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exts := Extensions(rm)
+	if len(exts) != 2 {
+		t.Fatalf("expected 2 extensions ; got %v", len(exts))
+	}
+	expected := []string{
+		"MPLS labels: 16014/tc=4/s=true/ttl=255",
+		"incoming interface: name=en101 index=15 mtu=8192 ip=192.168.0.1",
+	}
+	for i, ext := range exts {
+		if got := DescribeExtension(ext); got != expected[i] {
+			t.Errorf("expected <%v> ; got <%v>", expected[i], got)
+		}
+	}
+}
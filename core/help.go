@@ -9,12 +9,129 @@ var Usage = `
 Usage:
   goping www.usenix.org
   goping -c 2 8.8.4.4
+  goping -e eth0 192.0.2.1
+  goping --check -w 100,20% -c 250,60% www.usenix.org
+  goping --arp -I eth0 192.168.1.1
+  goping --nd -I eth0 fe80::1
+  goping -b -I eth0 224.0.0.1
+  goping serve -h
+  goping analyze -h
 
 Options:
+  --arp       Send ARP requests instead of ICMP. The target must be on-link. (OPTIONAL: Linux only)
+  -b          Allow pinging a broadcast or multicast address & report every responder. (OPTIONAL)
   -c count    Stop after sending count ECHO_REQUEST packets. (OPTIONAL: Defaults to 5.)
+  -c rtt,loss%
+              With --check, the critical thresholds, e.g. 250,60%. (OPTIONAL)
+  --check     Run as a Nagios or Icinga plugin: print one status line with performance data
+              & exit 0 (OK), 1 (WARNING), 2 (CRITICAL) or 3 (UNKNOWN). The average round
+              trip time in ms & the loss are held against -w & -c. Not with --format. (OPTIONAL)
+  --down-after n
+              Log the target as down after n consecutive losses. (OPTIONAL: Defaults to 3.)
+  -e ident    Query the status of interface ident on an IPv4 target (RFC 8335 PROBE).
+              ident is an interface name, ifIndex or IP address. (OPTIONAL)
+  --config f  Read the count, interval, timeout & size of echo requests to the target,
+              or of the defaults, from the TOML file f. -c takes precedence.
+              See goping serve -h for the format. (OPTIONAL)
+  --csv file  Also write one CSV row per probe to file. (OPTIONAL)
+  --csv-summary file
+              Write the CSV summary row to file. (OPTIONAL)
+  --format f  Output format: text, json (one summary document), ndjson (one event per line)
+              or csv (one row per probe). (OPTIONAL)
   -h          Show this message.
+  --influx d  Write InfluxDB line protocol to d: a file, - for stdout,
+              udp://host:port or http://host:8086/write?db=goping, POSTed every second. (OPTIONAL)
   -I iface    Interface iface is an interface name. E.g. eth0, docker0 (OPTIONAL)
+  --max-loss p
+              Exit 1 when more than p percent of the probes were lost. (OPTIONAL: Defaults to 100.)
+  --nd        Send IPv6 Neighbor Solicitations instead of ICMP. Requires -I. (OPTIONAL)
+  --neighbor  With -e address, look the address up in the neighbor table of the target
+              rather than among its own interfaces. (OPTIONAL)
+  --otlp url  Export OTLP metrics over HTTP to the collector at url, e.g. http://localhost:4318 (OPTIONAL)
+  --pcap file Record every echo request sent & ICMP packet received to the pcapng file; not with --arp, --nd or -b. (OPTIONAL)
+  --push-every d
+              Also send interim statistics to the collectors every d, e.g. 30s. (OPTIONAL)
+  -R          Record route. Routers on the path add their address to the reply. (OPTIONAL)
+  --reply-template t
+              Print each reply, timeout & ICMP error with the text/template t. (OPTIONAL)
+              E.g. --reply-template '{{.Seq}} {{.Peer}} {{ms .RTT}}ms'
+  --stats-template t
+              Print the rtt statistics line with t. (OPTIONAL)
+  --statsd a  Send StatsD counters & timers to a, host:port over UDP. (OPTIONAL)
+  --summary-every d
+              Print the statistics of every period d, e.g. 60s, without stopping.
+              CTRL+\ prints a running summary at any time. (OPTIONAL)
+  --summary-template t
+              Print the packet loss summary with t. (OPTIONAL)
+              A template starting with @ is read from that file instead.
+  -t ttl      Set the IP Time to Live. Multicast defaults to 1. (OPTIONAL)
+  -T tstype   Set the IP timestamp option; tstype is tsonly or tsandaddr. (OPTIONAL)
+  --up-after n
+              Log the target as up again after n consecutive replies. (OPTIONAL: Defaults to 1.)
   -v          Increase verbosity.
+  -w rtt,loss%
+              With --check, the warning thresholds, e.g. 100,20%. (OPTIONAL)
+  --windows w Keep sliding window statistics over each of the comma separated durations w,
+              shown with --summary-every & in machine readable summaries, next to moving
+              averages of the round trip time & loss. (OPTIONAL: Defaults to 1m,5m,15m.)
+
+Exit status:
+  0 when a reply arrived, 1 when none did or --max-loss was exceeded, 2 when no probe
+  could be sent or on other errors.
+  --check exits with the plugin status instead.
 
 Author: @GavinGastown3
 `
+
+// ServeUsage is the help blurb of goping serve
+var ServeUsage = `
+Usage:
+  goping serve 8.8.8.8 www.usenix.org
+  goping serve --listen :9427 -i 5s -W 2s 192.0.2.1
+  goping serve --module icmp5,count=5,interval=200ms,size=1400
+  curl 'localhost:9427/probe?target=www.usenix.org&module=icmp5'
+
+Options:
+  --config f  Also probe the targets of the TOML file f, with their settings & labels.
+              -i & -W take precedence over the file. (OPTIONAL)
+  -h          Show this message.
+  -I iface    Interface iface is an interface name. E.g. eth0, docker0 (OPTIONAL)
+  -i interval Send the count of echo requests of each target, one unless --config sets it,
+              every interval. (OPTIONAL: Defaults to 1s.)
+  --listen a  Serve /metrics & /probe on address a. (OPTIONAL: Defaults to :9427.)
+  --module m  Add a /probe module: name,count=N,interval=D,timeout=D,size=N,protocol=icmp
+              protocol icmp6 pings IPv6 targets. May be repeated.
+              The module icmp sends one 56 byte echo request. (OPTIONAL)
+  -W timeout  Time to wait for each reply. (OPTIONAL: Defaults to 1s.)
+  --windows w Export the loss & round trip times of each target over the sliding windows w.
+              (OPTIONAL: Defaults to 1m,5m,15m.)
+
+Config file:
+  [defaults]            # interval, count, size, timeout & protocol, as for --module
+  interval = "5s"
+  timeout = "2s"
+
+  [groups.dns]          # the targets of a group share its settings & labels
+  targets = ["8.8.8.8", "1.1.1.1"]
+  labels = { team = "infra" }
+
+  [targets."1.1.1.1"]   # overrides for one target, which need not be in a group
+  size = 1400
+`
+
+// AnalyzeUsage is the help blurb of goping analyze
+var AnalyzeUsage = `
+Usage:
+  goping analyze capture.pcap
+  goping analyze --target 192.0.2.1 probes.pcapng
+  tcpdump -i eth0 -w capture.pcap icmp
+
+Reads pcap & pcapng captures of ICMP echo traffic, e.g. from tcpdump or goping --pcap,
+and prints the statistics a live run would have printed for every target.
+Like a live run, only the first reply & error within the window of a probe count.
+
+Options:
+  -h          Show this message.
+  --target ip Only report target ip. (OPTIONAL)
+  -W window   Count replies up to window after their request. (OPTIONAL: Defaults to 5s.)
+`
@@ -0,0 +1,121 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tags describe the probe to collectors that
+// aggregate many goping instances.
+type Tags struct {
+	Target    string
+	IP        string
+	Interface string
+	Protocol  string
+}
+
+func (t Tags) pairs() [][2]string {
+	return [][2]string{
+		{"target", t.Target},
+		{"ip", t.IP},
+		{"interface", t.Interface},
+		{"protocol", t.Protocol},
+	}
+}
+
+var influxTagEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+var influxStringEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+
+// influxTags renders ",target=...,ip=..." skipping empty values.
+func influxTags(t Tags) string {
+	var b strings.Builder
+	for _, kv := range t.pairs() {
+		if kv[1] != "" {
+			fmt.Fprintf(&b, ",%s=%s", kv[0], influxTagEscaper.Replace(kv[1]))
+		}
+	}
+	return b.String()
+}
+
+// influxEvent renders a probe outcome in InfluxDB line protocol.
+func influxEvent(t Tags, e Event) string {
+	fields := []string{
+		"seq=" + strconv.Itoa(e.Seq) + "i",
+		`status="` + influxStringEscaper.Replace(e.Type) + `"`,
+	}
+	if e.Type == EventReply {
+		fields = append(fields, "rtt_ms="+formatFloat(float64(e.RTT)/float64(time.Millisecond)))
+	}
+	if e.TTL > 0 {
+		fields = append(fields, "ttl="+strconv.Itoa(e.TTL)+"i")
+	}
+	if e.ICMPType != 0 {
+		fields = append(fields, fmt.Sprintf("icmp_type=%di,icmp_code=%di", e.ICMPType, e.ICMPCode))
+	}
+	if e.Peer != "" {
+		fields = append(fields, `peer="`+influxStringEscaper.Replace(e.Peer)+`"`)
+	}
+	return fmt.Sprintf("goping%s %s %d\n", influxTags(t), strings.Join(fields, ","), e.Time.UnixNano())
+}
+
+// influxSummary renders the running totals in InfluxDB line protocol.
+func influxSummary(t Tags, s *Summary, when time.Time) string {
+	return fmt.Sprintf("goping_summary%s sent=%di,received=%di,errors=%di,loss_percent=%di,"+
+		"rtt_min_ms=%s,rtt_avg_ms=%s,rtt_max_ms=%s,rtt_mdev_ms=%s,"+
+		"rtt_p50_ms=%s,rtt_p90_ms=%s,rtt_p95_ms=%s,rtt_p99_ms=%s,rtt_p99_9_ms=%s %d\n",
+		influxTags(t), s.Sent, s.Received, s.Errors, s.Loss,
+		formatFloat(s.Min), formatFloat(s.Avg), formatFloat(s.Max), formatFloat(s.Mdev),
+		formatFloat(s.P50), formatFloat(s.P90), formatFloat(s.P95), formatFloat(s.P99), formatFloat(s.P999), when.UnixNano())
+}
+
+var statsdEscaper = strings.NewReplacer(":", "_", "|", "_", ",", "_", "#", "_", "\n", "_")
+
+// statsdTags renders DogStatsD style tags, "|#target:...,ip:...".
+func statsdTags(t Tags) string {
+	var tags []string
+	for _, kv := range t.pairs() {
+		if kv[1] != "" {
+			tags = append(tags, kv[0]+":"+statsdEscaper.Replace(kv[1]))
+		}
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+	return "|#" + strings.Join(tags, ",")
+}
+
+// statsdEvent renders a probe event as StatsD counters & timers.
+func statsdEvent(t Tags, e Event) string {
+	tags := statsdTags(t)
+	switch e.Type {
+	case EventSent:
+		return "goping.sent:1|c" + tags + "\n"
+	case EventReply:
+		return "goping.received:1|c" + tags + "\n" +
+			"goping.rtt:" + formatFloat(float64(e.RTT)/float64(time.Millisecond)) + "|ms" + tags + "\n"
+	case EventTimeout:
+		return "goping.timeout:1|c" + tags + "\n"
+	case EventError:
+		return "goping.icmp_error:1|c" + tags + "\n"
+	}
+	return ""
+}
+
+// statsdSummary renders the running totals as StatsD gauges.
+func statsdSummary(t Tags, s *Summary) string {
+	tags := statsdTags(t)
+	return fmt.Sprintf("goping.loss_percent:%d|g%s\n", s.Loss, tags) +
+		"goping.rtt_avg:" + formatFloat(s.Avg) + "|g" + tags + "\n" +
+		"goping.rtt_mdev:" + formatFloat(s.Mdev) + "|g" + tags + "\n" +
+		"goping.rtt_p50:" + formatFloat(s.P50) + "|g" + tags + "\n" +
+		"goping.rtt_p90:" + formatFloat(s.P90) + "|g" + tags + "\n" +
+		"goping.rtt_p95:" + formatFloat(s.P95) + "|g" + tags + "\n" +
+		"goping.rtt_p99:" + formatFloat(s.P99) + "|g" + tags + "\n" +
+		"goping.rtt_p99_9:" + formatFloat(s.P999) + "|g" + tags + "\n"
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var collectorTags = Tags{Target: "www example,com", IP: "192.0.2.1", Protocol: "icmp"}
+
+func TestInfluxLineProtocol(t *testing.T) {
+	var b bytes.Buffer
+	o, _ := NewOutput(&b, FormatInflux)
+	o.SetTags(collectorTags)
+	when := time.Unix(1500000000, 0)
+	o.Emit(Event{Type: EventSent, Time: when, Seq: 1})
+	o.Emit(Event{Type: EventReply, Time: when, Seq: 1, TTL: 57, RTT: 1500000, Peer: "192.0.2.1"})
+	o.Emit(Event{Type: EventTimeout, Time: when, Seq: 2})
+	expected := `goping,target=www\ example\,com,ip=192.0.2.1,protocol=icmp seq=1i,status="reply",rtt_ms=1.5,ttl=57i,peer="192.0.2.1" 1500000000000000000` + "\n" +
+		`goping,target=www\ example\,com,ip=192.0.2.1,protocol=icmp seq=2i,status="timeout" 1500000000000000000` + "\n"
+	if b.String() != expected {
+		t.Errorf("expected <%v> ; got <%v>", expected, b.String())
+	}
+	b.Reset()
+	o.Finish(&Summary{Sent: 2, Received: 1, Loss: 50, Min: 1.5, Avg: 1.5, Max: 1.5})
+	if !strings.HasPrefix(b.String(), `goping_summary,target=www\ example\,com,ip=192.0.2.1,protocol=icmp sent=2i,received=1i,errors=0i,loss_percent=50i,rtt_min_ms=1.5,`) {
+		t.Errorf("unexpected summary <%v>", b.String())
+	}
+}
+
+func TestInfluxOverHTTP(t *testing.T) {
+	var lock sync.Mutex
+	var received bytes.Buffer
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		lock.Lock()
+		defer lock.Unlock()
+		_, _ = io.Copy(&received, r.Body)
+	}))
+	defer slow.Close()
+
+	w, err := OpenDestination(slow.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, _ := NewOutput(w, FormatInflux)
+	o.SetTags(collectorTags)
+	start := time.Now()
+	for seq := 1; seq <= 3; seq++ {
+		o.Emit(Event{Type: EventReply, Seq: seq, RTT: 1500000})
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Emit waited %v for the collector", elapsed)
+	}
+	o.Finish(&Summary{Sent: 3, Received: 3})
+
+	lock.Lock()
+	defer lock.Unlock()
+	for _, line := range []string{"seq=1i", "seq=2i", "seq=3i", "goping_summary,"} {
+		if !strings.Contains(received.String(), line) {
+			t.Errorf("missing <%v> in\n%v", line, received.String())
+		}
+	}
+}
+
+func TestStatsDOverUDP(t *testing.T) {
+	collector, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer collector.Close()
+
+	w, err := OpenDestination("udp://" + collector.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	o, _ := NewOutput(w, FormatStatsD)
+	o.SetTags(collectorTags)
+	o.Emit(Event{Type: EventReply, Seq: 1, RTT: 2500000})
+
+	collector.SetDeadline(time.Now().Add(5 * time.Second))
+	b := make([]byte, 1500)
+	n, _, err := collector.ReadFrom(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags := "|#target:www example_com,ip:192.0.2.1,protocol:icmp"
+	expected := "goping.received:1|c" + tags + "\ngoping.rtt:2.5|ms" + tags + "\n"
+	if string(b[:n]) != expected {
+		t.Errorf("expected <%v> ; got <%v>", expected, string(b[:n]))
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"golang.org/x/net/ipv4"
+	"net"
+	"strings"
+)
+
+// IPv4 option types (RFC 791)
+const (
+	optEndOfList   = 0
+	optNoOperation = 1
+	optRecordRoute = 7
+	optTimestamp   = 68
+)
+
+// maxOptionsLen is the room left in a 60 byte IPv4 header.
+const maxOptionsLen = 40
+
+// Timestamp option flags
+const (
+	tsOnly    = 0
+	tsAndAddr = 1
+)
+
+// ErrBadTimestamp means -T was given something other than tsonly or tsandaddr.
+var ErrBadTimestamp = errors.New("invalid timestamp type")
+
+// ErrTooManyOptions means both -R and -T were requested.
+// They cannot share the 40 bytes of IPv4 option space.
+var ErrTooManyOptions = errors.New("only one of -R or -T may be used")
+
+// NewRecordRoute returns a Record Route option with room for 9 hops.
+func NewRecordRoute() []byte {
+	b := make([]byte, maxOptionsLen)
+	b[0] = optRecordRoute
+	b[1] = maxOptionsLen - 1
+	b[2] = 4
+	return b
+}
+
+// NewTimestamp returns an Internet Timestamp option.
+// The kind is either "tsonly" or "tsandaddr".
+func NewTimestamp(kind string) ([]byte, error) {
+	b := make([]byte, maxOptionsLen)
+	b[0] = optTimestamp
+	b[2] = 5
+	switch kind {
+	case "tsonly":
+		b[1] = maxOptionsLen
+		b[3] = tsOnly
+	case "tsandaddr":
+		b[1] = maxOptionsLen - 4
+		b[3] = tsAndAddr
+	default:
+		return nil, ErrBadTimestamp
+	}
+	return b, nil
+}
+
+// NewIPOptions returns the options requested on the command line,
+// or nil when there are none.
+func NewIPOptions(recordRoute bool, timestamp string) ([]byte, error) {
+	if recordRoute && timestamp != "" {
+		return nil, ErrTooManyOptions
+	}
+	if recordRoute {
+		return NewRecordRoute(), nil
+	}
+	if timestamp != "" {
+		return NewTimestamp(timestamp)
+	}
+	return nil, nil
+}
+
+// NewIPv4Header constructs the header for an ICMP packet
+// carrying options. The kernel fills in the source, ID & checksum.
+func NewIPv4Header(dst net.IP, options []byte, payloadLen int) *ipv4.Header {
+	return &ipv4.Header{
+		Version:  ipv4.Version,
+		Len:      ipv4.HeaderLen + len(options),
+		TotalLen: ipv4.HeaderLen + len(options) + payloadLen,
+		TTL:      64,
+		Protocol: 1,
+		Dst:      dst,
+		Options:  options,
+	}
+}
+
+// Timestamp is one entry of an Internet Timestamp option.
+// Addr is nil for tsonly.
+type Timestamp struct {
+	Addr   net.IP
+	Millis uint32
+}
+
+// IPOptions holds what routers recorded in a reply header.
+type IPOptions struct {
+	Route      []net.IP
+	Timestamps []Timestamp
+	Overflow   int
+}
+
+// ParseIPOptions decodes the Record Route & Timestamp options.
+// Unknown or truncated options are skipped.
+func ParseIPOptions(b []byte) *IPOptions {
+	o := new(IPOptions)
+	for i := 0; i < len(b); {
+		switch b[i] {
+		case optEndOfList:
+			return o
+		case optNoOperation:
+			i++
+			continue
+		}
+		if i+1 >= len(b) || b[i+1] < 2 || i+int(b[i+1]) > len(b) {
+			return o
+		}
+		opt := b[i : i+int(b[i+1])]
+		switch opt[0] {
+		case optRecordRoute:
+			if len(opt) < 3 {
+				break
+			}
+			for j := 3; j+4 <= int(opt[2])-1 && j+4 <= len(opt); j += 4 {
+				o.Route = append(o.Route, net.IP(append([]byte(nil), opt[j:j+4]...)))
+			}
+		case optTimestamp:
+			if len(opt) < 4 {
+				break
+			}
+			o.Overflow = int(opt[3] >> 4)
+			step := 4
+			if opt[3]&0x0f != tsOnly {
+				step = 8
+			}
+			for j := 4; j+step <= int(opt[2])-1 && j+step <= len(opt); j += step {
+				var ts Timestamp
+				if step == 8 {
+					ts.Addr = net.IP(append([]byte(nil), opt[j:j+4]...))
+				}
+				ts.Millis = binary.BigEndian.Uint32(opt[j+step-4 : j+step])
+				o.Timestamps = append(o.Timestamps, ts)
+			}
+		}
+		i += len(opt)
+	}
+	return o
+}
+
+// String formats the recorded hops the way iputils does.
+func (o *IPOptions) String() string {
+	var lines []string
+	for i, ip := range o.Route {
+		if i == 0 {
+			lines = append(lines, "RR: \t"+ip.String())
+		} else {
+			lines = append(lines, "\t"+ip.String())
+		}
+	}
+	for i, ts := range o.Timestamps {
+		prefix := "\t"
+		if i == 0 {
+			prefix = "TS: \t"
+		}
+		if ts.Addr != nil {
+			lines = append(lines, fmt.Sprintf("%s%v\t%d absolute", prefix, ts.Addr, ts.Millis))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s%d absolute", prefix, ts.Millis))
+		}
+	}
+	if o.Overflow > 0 {
+		lines = append(lines, fmt.Sprintf("Unrecorded hops: %d", o.Overflow))
+	}
+	return strings.Join(lines, "\n")
+}
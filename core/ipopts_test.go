@@ -0,0 +1,46 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParseRecordRoute(t *testing.T) {
+	b := NewRecordRoute()
+	copy(b[3:], []byte{10, 0, 0, 1, 10, 0, 0, 2})
+	b[2] = 4 + 8
+	o := ParseIPOptions(b)
+	if len(o.Route) != 2 || !o.Route[1].Equal(net.IPv4(10, 0, 0, 2)) {
+		t.Errorf("unexpected route %v", o.Route)
+	}
+	if o.String() != "RR: \t10.0.0.1\n\t10.0.0.2" {
+		t.Errorf("unexpected rendering <%v>", o.String())
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	b, err := NewTimestamp("tsandaddr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(b[4:], []byte{192, 0, 2, 1, 0, 0, 0x03, 0xe8})
+	b[2] = 5 + 8
+	b[3] |= 2 << 4
+	o := ParseIPOptions(b)
+	if len(o.Timestamps) != 1 || o.Timestamps[0].Millis != 1000 || o.Overflow != 2 {
+		t.Errorf("unexpected timestamps %+v", o)
+	}
+	if _, err := NewTimestamp("tsprespec"); err != ErrBadTimestamp {
+		t.Errorf("expected %v ; got %v", ErrBadTimestamp, err)
+	}
+}
+
+func TestParseConflictingOptions(t *testing.T) {
+	if _, err := ParseArgs([]string{"-R", "-T", "tsonly", "localhost"}); err != ErrTooManyOptions {
+		t.Errorf("expected %v ; got %v", ErrTooManyOptions, err)
+	}
+}
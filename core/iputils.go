@@ -61,3 +61,14 @@ func ScanInterfaces() (map[string]net.IP, error) {
 	}
 	return retval, nil
 }
+
+// SourceAddr returns the local IPv4 address the kernel routes dst
+// from, or nil. Connecting a UDP socket sends nothing.
+func SourceAddr(dst net.IP) net.IP {
+	c, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: dst, Port: 9})
+	if err != nil {
+		return nil
+	}
+	defer c.Close()
+	return c.LocalAddr().(*net.UDPAddr).IP
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/thirdparty"
+	"github.com/erriapo/stats"
+	"math"
+	"sync"
+)
+
+// Jitter follows the variation between the round trip times
+// of consecutive replies as they arrive. Times are in milliseconds.
+// It is safe for concurrent use.
+type Jitter struct {
+	lock   sync.Mutex
+	have   bool
+	last   float64
+	jitter float64
+	sum    float64
+	max    float64
+	deltas *stats.WelfordSink
+}
+
+// NewJitter constructs an empty Jitter.
+func NewJitter() *Jitter {
+	return &Jitter{deltas: stats.NewSink()}
+}
+
+// Push adds the round trip time of the next reply.
+func (j *Jitter) Push(rtt float64) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if !j.have {
+		j.have, j.last = true, rtt
+		return
+	}
+	d := rtt - j.last
+	j.last = rtt
+	_ = j.deltas.Push(d)
+	// RFC 3550 section 6.4.1: J += (|D| - J) / 16
+	j.jitter += (math.Abs(d) - j.jitter) / 16
+	j.sum += math.Abs(d)
+	j.max = math.Max(j.max, math.Abs(d))
+}
+
+// Jitter returns the RFC 3550 interarrival jitter.
+func (j *Jitter) Jitter() float64 {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	return j.jitter
+}
+
+// IPDV returns the mean & maximum absolute delay variation
+// between consecutive replies (RFC 3393).
+func (j *Jitter) IPDV() (mean, max float64) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if n := j.deltas.Count(); n > 0 {
+		mean = j.sum / float64(n)
+	}
+	return mean, j.max
+}
+
+// Format renders a line to go with thirdparty.Format's, e.g.
+// "jitter = 0.12 ms, ipdv mean/max = 0.2/0.5 ms, rtt delta min/avg/max/mdev = -0.5/0.01/0.4/0.3 ms".
+// Deltas are signed, the later reply's round trip minus the earlier one's.
+func (j *Jitter) Format() string {
+	mean, max := j.IPDV()
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	deltas := "0/0/0/0"
+	if j.deltas.Count() > 0 {
+		deltas = fmt.Sprintf("%v/%v/%v/%v", thirdparty.ToFixed(j.deltas.Min(), 3), thirdparty.ToFixed(j.deltas.Mean(), 3),
+			thirdparty.ToFixed(j.deltas.Max(), 3), thirdparty.ToFixed(j.deltas.StandardDeviation(), 3))
+	}
+	return fmt.Sprintf("jitter = %v ms, ipdv mean/max = %v/%v ms, rtt delta min/avg/max/mdev = %s ms",
+		thirdparty.ToFixed(j.jitter, 3), thirdparty.ToFixed(mean, 3), thirdparty.ToFixed(max, 3), deltas)
+}
+
+// EModel estimates the R-factor & Mean Opinion Score of a voice call
+// over the path with the simplified ITU-T G.107 E-model network
+// monitoring tools use: latency & jitter in ms, loss in percent.
+func EModel(latency, jitter, loss float64) (r, mos float64) {
+	effective := latency + 2*jitter + 10
+	if effective < 160 {
+		r = 93.2 - effective/40
+	} else {
+		r = 93.2 - (effective-120)/10
+	}
+	r -= 2.5 * loss
+	r = math.Max(0, math.Min(100, r))
+	mos = 1 + 0.035*r + 0.000007*r*(r-60)*(100-r)
+	return r, mos
+}
+
+// SetJitter adds the jitter statistics & the E-model estimate
+// computed from them, s.Avg & s.Loss.
+func (s *Summary) SetJitter(j *Jitter) {
+	s.Jitter = j.Jitter()
+	s.IPDVMean, s.IPDVMax = j.IPDV()
+	if s.Received > 0 {
+		s.RFactor, s.MOS = EModel(s.Avg, s.Jitter, s.ExactLoss())
+	}
+}
+
+// FormatEModel renders the E-model estimate of s, e.g. "R-factor = 92.7, MOS = 4.4".
+func FormatEModel(s *Summary) string {
+	return fmt.Sprintf("R-factor = %v, MOS = %v", thirdparty.ToFixed(s.RFactor, 1), thirdparty.ToFixed(s.MOS, 2))
+}
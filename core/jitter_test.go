@@ -0,0 +1,62 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestJitter(t *testing.T) {
+	j := NewJitter()
+	for _, rtt := range []float64{10, 12, 11, 15} {
+		j.Push(rtt)
+	}
+	// D = 2, -1, 4
+	expected := 0.0
+	for _, d := range []float64{2, 1, 4} {
+		expected += (d - expected) / 16
+	}
+	if math.Abs(j.Jitter()-expected) > 1e-12 {
+		t.Errorf("expected jitter %v ; got %v", expected, j.Jitter())
+	}
+	if mean, max := j.IPDV(); mean != 7.0/3 || max != 4 {
+		t.Errorf("expected ipdv 2.333/4 ; got %v/%v", mean, max)
+	}
+	if !strings.Contains(j.Format(), "rtt delta min/avg/max/mdev = -1/1.667/4/") {
+		t.Errorf("unexpected <%v>", j.Format())
+	}
+
+	s := &Summary{Received: 4, Avg: 12, Loss: 0}
+	s.SetJitter(j)
+	if s.Jitter != j.Jitter() || s.IPDVMax != 4 || s.RFactor < 90 || s.MOS < 4.3 {
+		t.Errorf("unexpected summary %+v", s)
+	}
+
+	// the E-model takes the loss before truncation
+	s = &Summary{Sent: 3, Received: 2, Avg: 12, Loss: 33}
+	s.SetJitter(j)
+	if r, _ := EModel(12, j.Jitter(), 100.0/3); s.RFactor != r {
+		t.Errorf("expected R-factor %v ; got %v", r, s.RFactor)
+	}
+}
+
+func TestEModel(t *testing.T) {
+	for _, tc := range []struct {
+		latency, jitter, loss float64
+		r, mos                float64
+	}{
+		{0, 0, 0, 92.95, 4.404},
+		{150, 10, 0, 87.2, 4.265},
+		{300, 20, 5, 57.7, 2.98},
+		{1000, 100, 50, 0, 1},
+	} {
+		r, mos := EModel(tc.latency, tc.jitter, tc.loss)
+		if math.Abs(r-tc.r) > 0.01 || math.Abs(mos-tc.mos) > 0.01 {
+			t.Errorf("EModel(%v, %v, %v): expected %v/%v ; got %v/%v", tc.latency, tc.jitter, tc.loss, tc.r, tc.mos, r, mos)
+		}
+	}
+}
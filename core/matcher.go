@@ -0,0 +1,141 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"net"
+	"time"
+)
+
+// DefaultWindow is how long a probe waits for its reply.
+const DefaultWindow = 5 * time.Second
+
+// Verdict tells what a received packet is to the probes sent.
+type Verdict int
+
+const (
+	// Matched answers a probe for the first time.
+	Matched Verdict = iota
+	// Duplicate answers a probe already answered.
+	Duplicate
+	// Late answers a probe after its window.
+	Late
+	// Unmatched answers no probe sent, e.g. one of another process.
+	Unmatched
+	// Ignored answers nothing, e.g. an echo request.
+	Ignored
+)
+
+var verdicts = []string{"matched", "duplicate", "late", "unmatched", "ignored"}
+
+func (v Verdict) String() string {
+	return verdicts[v]
+}
+
+// Match is the probe a reply or an ICMP error answers.
+type Match struct {
+	Target string
+	Seq    int
+	RTT    time.Duration
+	// Error is set for ICMP errors quoting the request.
+	Error bool
+}
+
+// probeKey identifies one echo request.
+type probeKey struct {
+	target string
+	id     int
+	seq    int
+}
+
+// Matcher pairs echo requests with the replies & ICMP errors answering
+// them. A live run & goping analyze share it so that both count alike:
+// the first reply & the first error within Window of the request.
+// As an echo reply may still follow an error, e.g. after a redirect,
+// the two are kept apart.
+type Matcher struct {
+	Window   time.Duration
+	pending  map[probeKey]time.Time
+	answered map[probeKey]bool
+	errored  map[probeKey]bool
+}
+
+// NewMatcher constructs a Matcher waiting window for every reply.
+func NewMatcher(window time.Duration) *Matcher {
+	return &Matcher{
+		Window:   window,
+		pending:  make(map[probeKey]time.Time),
+		answered: make(map[probeKey]bool),
+		errored:  make(map[probeKey]bool),
+	}
+}
+
+// IsEchoRequest tells whether typ is an echo or extended echo request.
+func IsEchoRequest(typ icmp.Type) bool {
+	switch typ {
+	case ipv4.ICMPTypeEcho, ipv4.ICMPTypeExtendedEchoRequest,
+		ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeExtendedEchoRequest:
+		return true
+	}
+	return false
+}
+
+// Sent registers the request rm sent to dst at t. It returns false
+// when rm is no request or is already waiting, e.g. captured twice.
+func (m *Matcher) Sent(dst net.IP, rm *icmp.Message, t time.Time) bool {
+	if !IsEchoRequest(rm.Type) {
+		return false
+	}
+	var key probeKey
+	switch body := rm.Body.(type) {
+	case *icmp.Echo:
+		key = probeKey{dst.String(), body.ID, body.Seq}
+	case *icmp.ExtendedEchoRequest:
+		key = probeKey{dst.String(), body.ID, body.Seq}
+	default:
+		return false
+	}
+	if sent, ok := m.pending[key]; ok && t.Sub(sent) <= m.Window {
+		return false
+	}
+	// a sequence number may come round again
+	m.pending[key] = t
+	delete(m.answered, key)
+	delete(m.errored, key)
+	return true
+}
+
+// Match pairs rm, received from src at t, with the request it answers.
+func (m *Matcher) Match(rm *icmp.Message, src net.IP, t time.Time) (Match, Verdict) {
+	id, seq, quotedDst, ok := MatchReply(rm)
+	if !ok {
+		return Match{}, Ignored
+	}
+	target := src.String()
+	if quotedDst != nil {
+		target = quotedDst.String()
+	}
+	key := probeKey{target, id, seq}
+	sent, ok := m.pending[key]
+	if !ok {
+		return Match{}, Unmatched
+	}
+	match := Match{Target: target, Seq: seq, RTT: t.Sub(sent), Error: quotedDst != nil}
+	if match.RTT > m.Window {
+		return match, Late
+	}
+	seen := m.answered
+	if match.Error {
+		seen = m.errored
+	}
+	if seen[key] {
+		return match, Duplicate
+	}
+	seen[key] = true
+	return match, Matched
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestMatcher(t *testing.T) {
+	there := net.IPv4(192, 0, 2, 1)
+	start := time.Unix(1500000000, 0)
+	echo := func(typ icmp.Type, id, seq int) *icmp.Message {
+		return &icmp.Message{Type: typ, Body: &icmp.Echo{ID: id, Seq: seq}}
+	}
+	m := NewMatcher(time.Second)
+	if !m.Sent(there, echo(ipv4.ICMPTypeEcho, 9, 1), start) {
+		t.Errorf("expected the request to register")
+	}
+	if m.Sent(there, echo(ipv4.ICMPTypeEcho, 9, 1), start) {
+		t.Errorf("expected a request captured twice to register once")
+	}
+	if m.Sent(there, echo(ipv4.ICMPTypeEchoReply, 9, 2), start) {
+		t.Errorf("expected a reply not to register")
+	}
+
+	cases := []struct {
+		rm      *icmp.Message
+		src     net.IP
+		after   time.Duration
+		verdict Verdict
+	}{
+		{echo(ipv4.ICMPTypeEcho, 9, 1), there, 0, Ignored},
+		{echo(ipv4.ICMPTypeEchoReply, 10, 1), there, 0, Unmatched},
+		{echo(ipv4.ICMPTypeEchoReply, 9, 1), net.IPv4(192, 0, 2, 9), 0, Unmatched},
+		{echo(ipv4.ICMPTypeEchoReply, 9, 1), there, 3 * time.Millisecond, Matched},
+		{echo(ipv4.ICMPTypeEchoReply, 9, 1), there, 4 * time.Millisecond, Duplicate},
+		{echo(ipv4.ICMPTypeEchoReply, 9, 1), there, 2 * time.Second, Late},
+	}
+	for _, c := range cases {
+		match, verdict := m.Match(c.rm, c.src, start.Add(c.after))
+		if verdict != c.verdict {
+			t.Errorf("expected %v ; got %v for %+v", c.verdict, verdict, c)
+		}
+		if verdict == Matched && (match.Target != "192.0.2.1" || match.Seq != 1 || match.RTT != c.after || match.Error) {
+			t.Errorf("unexpected %+v", match)
+		}
+	}
+
+	// a sequence number coming round again is a new probe
+	if !m.Sent(there, echo(ipv4.ICMPTypeEcho, 9, 1), start.Add(time.Minute)) {
+		t.Errorf("expected the request to register again")
+	}
+	if _, verdict := m.Match(echo(ipv4.ICMPTypeEchoReply, 9, 1), there, start.Add(time.Minute)); verdict != Matched {
+		t.Errorf("expected %v ; got %v", Matched, verdict)
+	}
+}
+
+func TestMatcherExtendedEcho(t *testing.T) {
+	there := net.IPv4(192, 0, 2, 1)
+	start := time.Unix(1500000000, 0)
+	wm := NewExtendedEcho("eth0", false, 300)
+	m := NewMatcher(DefaultWindow)
+	if !m.Sent(there, &wm, start) {
+		t.Fatalf("expected the request to register")
+	}
+	id := wm.Body.(*icmp.ExtendedEchoRequest).ID
+	rm := &icmp.Message{Type: ipv4.ICMPTypeExtendedEchoReply, Body: &icmp.ExtendedEchoReply{ID: id, Seq: 300 & 0xff, Active: true}}
+	if match, verdict := m.Match(rm, there, start); verdict != Matched || match.Seq != 300&0xff {
+		t.Errorf("unexpected %+v %v", match, verdict)
+	}
+}
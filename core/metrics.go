@@ -0,0 +1,247 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RTTBuckets are the upper bounds, in seconds,
+// of the goping_rtt_seconds histogram.
+var RTTBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// icmpError identifies an ICMP error by type & code.
+type icmpError struct {
+	typ  int
+	code int
+}
+
+// TargetMetrics is what the exporter knows about one target.
+type TargetMetrics struct {
+	Target  string
+	counter *Counter
+	lastRTT float64
+	buckets []uint64
+	sum     float64
+	rtts    *Quantiles
+	windows *Windows
+	errors  map[icmpError]uint64
+	labels  string
+}
+
+// Metrics holds the TargetMetrics of every probed target
+// and renders them in the Prometheus text exposition format.
+type Metrics struct {
+	lock    sync.Mutex
+	targets map[string]*TargetMetrics
+	clock   Clock
+	spans   []time.Duration
+}
+
+// NewMetrics constructs an empty Metrics keeping the DefaultWindows.
+func NewMetrics() *Metrics {
+	return &Metrics{targets: make(map[string]*TargetMetrics), clock: SystemClock, spans: DefaultWindows}
+}
+
+// SetWindows changes the sliding windows of the targets yet to be observed.
+func (m *Metrics) SetWindows(clock Clock, spans []time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.clock, m.spans = clock, spans
+}
+
+func (m *Metrics) target(name string) *TargetMetrics {
+	t, ok := m.targets[name]
+	if !ok {
+		t = &TargetMetrics{
+			Target:  name,
+			counter: NewCounter(),
+			buckets: make([]uint64, len(RTTBuckets)),
+			rtts:    NewQuantiles(),
+			windows: NewWindows(m.clock, m.spans),
+			errors:  make(map[icmpError]uint64),
+		}
+		m.targets[name] = t
+	}
+	return t
+}
+
+// SetLabels adds the group & labels of a --config target to its metrics.
+func (m *Metrics) SetLabels(target, group string, labels map[string]string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	t := m.target(target)
+	t.labels = formatLabels(labels)
+	if group != "" {
+		t.labels = fmt.Sprintf(",group=\"%s\"%s", escapeLabel(group), t.labels)
+	}
+}
+
+// Observe records the outcome of one probe of target.
+func (m *Metrics) Observe(target string, r Result) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	t := m.target(target)
+	if r.Err != nil && r.Err != ErrTimeout {
+		// never left this host
+		return
+	}
+	t.counter.OnSent()
+	e := Event{Type: EventTimeout, RTT: int64(r.RTT)}
+	switch {
+	case r.Type == nil:
+	case IsEchoReply(r.Type):
+		e.Type = EventReply
+		t.counter.OnReception()
+		rtt := r.RTT.Seconds()
+		t.lastRTT = rtt
+		t.sum += rtt
+		t.rtts.Push(rtt)
+		for i, le := range RTTBuckets {
+			if rtt <= le {
+				t.buckets[i]++
+			}
+		}
+	default:
+		e.Type = EventError
+		t.counter.NoteAnError()
+		switch typ := r.Type.(type) {
+		case ipv4.ICMPType:
+			t.errors[icmpError{int(typ), r.Code}]++
+		case ipv6.ICMPType:
+			t.errors[icmpError{int(typ), r.Code}]++
+		}
+	}
+	t.windows.Observe(e)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// escapeLabel escapes a label value for the text format.
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// Render writes every metric in the Prometheus text format.
+func (m *Metrics) Render(w io.Writer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	names := make([]string, 0, len(m.targets))
+	for name := range m.targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type family struct {
+		name, kind, help string
+		write            func(t *TargetMetrics, label string)
+	}
+	families := []family{
+		{"goping_probes_sent_total", "counter", "ICMP echo requests sent.",
+			func(t *TargetMetrics, label string) {
+				sent, _, _ := t.counter.totals()
+				fmt.Fprintf(w, "goping_probes_sent_total{%s} %d\n", label, sent)
+			}},
+		{"goping_probes_received_total", "counter", "ICMP echo replies received.",
+			func(t *TargetMetrics, label string) {
+				_, recvd, _ := t.counter.totals()
+				fmt.Fprintf(w, "goping_probes_received_total{%s} %d\n", label, recvd)
+			}},
+		{"goping_loss_ratio", "gauge", "Fraction of probes without a reply.",
+			func(t *TargetMetrics, label string) {
+				sent, recvd, _ := t.counter.totals()
+				loss := 0.0
+				if sent > 0 {
+					loss = float64(sent-recvd) / float64(sent)
+				}
+				fmt.Fprintf(w, "goping_loss_ratio{%s} %s\n", label, formatFloat(loss))
+			}},
+		{"goping_rtt_last_seconds", "gauge", "Round trip time of the latest reply.",
+			func(t *TargetMetrics, label string) {
+				fmt.Fprintf(w, "goping_rtt_last_seconds{%s} %s\n", label, formatFloat(t.lastRTT))
+			}},
+		{"goping_rtt_seconds", "histogram", "Round trip times of echo replies.",
+			func(t *TargetMetrics, label string) {
+				for i, le := range RTTBuckets {
+					fmt.Fprintf(w, "goping_rtt_seconds_bucket{%s,le=\"%s\"} %d\n", label, formatFloat(le), t.buckets[i])
+				}
+				_, recvd, _ := t.counter.totals()
+				fmt.Fprintf(w, "goping_rtt_seconds_bucket{%s,le=\"+Inf\"} %d\n", label, recvd)
+				fmt.Fprintf(w, "goping_rtt_seconds_sum{%s} %s\n", label, formatFloat(t.sum))
+				fmt.Fprintf(w, "goping_rtt_seconds_count{%s} %d\n", label, recvd)
+			}},
+		{"goping_rtt_summary_seconds", "summary", "Percentiles of the round trip times of echo replies.",
+			func(t *TargetMetrics, label string) {
+				_, recvd, _ := t.counter.totals()
+				if recvd > 0 {
+					for _, p := range Percentiles {
+						fmt.Fprintf(w, "goping_rtt_summary_seconds{%s,quantile=\"%s\"} %s\n", label, formatFloat(quantile(p)), formatFloat(t.rtts.Percentile(p)))
+					}
+				}
+				fmt.Fprintf(w, "goping_rtt_summary_seconds_sum{%s} %s\n", label, formatFloat(t.sum))
+				fmt.Fprintf(w, "goping_rtt_summary_seconds_count{%s} %d\n", label, recvd)
+			}},
+		{"goping_window_loss_ratio", "gauge", "Fraction of the probes of each sliding window without a reply.",
+			func(t *TargetMetrics, label string) {
+				for _, ws := range t.windows.Report().Windows {
+					fmt.Fprintf(w, "goping_window_loss_ratio{%s,window=\"%s\"} %s\n", label, ws.Span, formatFloat(ws.Loss/100))
+				}
+			}},
+		{"goping_window_rtt_avg_seconds", "gauge", "Mean round trip time of the replies of each sliding window.",
+			func(t *TargetMetrics, label string) {
+				for _, ws := range t.windows.Report().Windows {
+					fmt.Fprintf(w, "goping_window_rtt_avg_seconds{%s,window=\"%s\"} %s\n", label, ws.Span, formatFloat(ws.Avg/1000))
+				}
+			}},
+		{"goping_window_rtt_max_seconds", "gauge", "Largest round trip time of the replies of each sliding window.",
+			func(t *TargetMetrics, label string) {
+				for _, ws := range t.windows.Report().Windows {
+					fmt.Fprintf(w, "goping_window_rtt_max_seconds{%s,window=\"%s\"} %s\n", label, ws.Span, formatFloat(ws.Max/1000))
+				}
+			}},
+		{"goping_rtt_ewma_seconds", "gauge", "Exponentially weighted moving average of the round trip time.",
+			func(t *TargetMetrics, label string) {
+				fmt.Fprintf(w, "goping_rtt_ewma_seconds{%s} %s\n", label, formatFloat(t.windows.Report().EWMA.RTT/1000))
+			}},
+		{"goping_loss_ewma_ratio", "gauge", "Exponentially weighted moving average of the loss.",
+			func(t *TargetMetrics, label string) {
+				fmt.Fprintf(w, "goping_loss_ewma_ratio{%s} %s\n", label, formatFloat(t.windows.Report().EWMA.Loss/100))
+			}},
+		{"goping_icmp_errors_total", "counter", "ICMP errors received in response to probes.",
+			func(t *TargetMetrics, label string) {
+				keys := make([]icmpError, 0, len(t.errors))
+				for k := range t.errors {
+					keys = append(keys, k)
+				}
+				sort.Slice(keys, func(i, j int) bool {
+					return keys[i].typ < keys[j].typ || keys[i].typ == keys[j].typ && keys[i].code < keys[j].code
+				})
+				for _, k := range keys {
+					fmt.Fprintf(w, "goping_icmp_errors_total{%s,type=\"%d\",code=\"%d\"} %d\n", label, k.typ, k.code, t.errors[k])
+				}
+			}},
+	}
+	for _, f := range families {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+		for _, name := range names {
+			t := m.targets[name]
+			f.write(t, fmt.Sprintf("target=\"%s\"%s", escapeLabel(name), t.labels))
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"golang.org/x/net/ipv4"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsRender(t *testing.T) {
+	m := NewMetrics()
+	m.Observe("b.example", Result{Type: ipv4.ICMPTypeEchoReply, RTT: 3 * time.Millisecond})
+	m.Observe("b.example", Result{Err: ErrTimeout})
+	m.Observe("b.example", Result{Type: ipv4.ICMPTypeDestinationUnreachable, Code: 1})
+	m.Observe("a.example", Result{Err: errors.New("connect: network is unreachable")})
+	var b bytes.Buffer
+	m.Render(&b)
+	for _, line := range []string{
+		"# TYPE goping_rtt_seconds histogram",
+		`goping_probes_sent_total{target="a.example"} 0`,
+		`goping_probes_sent_total{target="b.example"} 3`,
+		`goping_probes_received_total{target="b.example"} 1`,
+		`goping_loss_ratio{target="b.example"} 0.6666666666666666`,
+		`goping_rtt_last_seconds{target="b.example"} 0.003`,
+		`goping_rtt_seconds_bucket{target="b.example",le="0.0025"} 0`,
+		`goping_rtt_seconds_bucket{target="b.example",le="0.005"} 1`,
+		`goping_rtt_seconds_bucket{target="b.example",le="+Inf"} 1`,
+		`goping_rtt_seconds_count{target="b.example"} 1`,
+		`goping_icmp_errors_total{target="b.example",type="3",code="1"} 1`,
+		`goping_rtt_summary_seconds{target="b.example",quantile="0.99"} 0.003`,
+		`goping_rtt_summary_seconds{target="b.example",quantile="0.999"} 0.003`,
+		`goping_rtt_summary_seconds_count{target="b.example"} 1`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("missing <%v> in\n%v", line, b.String())
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/erriapo/goping/thirdparty"
+	"github.com/erriapo/stats"
+	"io"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrBadModule means a --module specification could not be parsed.
+var ErrBadModule = errors.New("bad module")
+
+// ErrUnknownModule means /probe was asked for a module that is not configured.
+var ErrUnknownModule = errors.New("unknown module")
+
+// ErrBadProtocol means the module asks for a protocol goping cannot probe with.
+var ErrBadProtocol = errors.New("unsupported protocol, expected icmp or icmp6")
+
+// Module is a named probe configuration for the /probe endpoint,
+// in the spirit of blackbox_exporter modules.
+type Module struct {
+	Name     string
+	Count    uint64
+	Interval time.Duration
+	Timeout  time.Duration
+	Size     int
+	Protocol string
+}
+
+// NewModule returns a module sending a single 56 byte echo request.
+func NewModule(name string) *Module {
+	return &Module{
+		Name:     name,
+		Count:    1,
+		Interval: time.Second,
+		Timeout:  time.Second,
+		Size:     56,
+		Protocol: "icmp",
+	}
+}
+
+// ParseModule parses "name,count=3,interval=200ms,timeout=1s,size=56,protocol=icmp".
+// Omitted settings keep their defaults.
+func ParseModule(spec string) (*Module, error) {
+	fields := strings.Split(spec, ",")
+	if fields[0] == "" {
+		return nil, fmt.Errorf("%v %q: missing name", ErrBadModule, spec)
+	}
+	m := NewModule(fields[0])
+	for _, field := range fields[1:] {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%v %q: %q is not key=value", ErrBadModule, spec, field)
+		}
+		if err := m.set(kv[0], kv[1]); err != nil {
+			return nil, fmt.Errorf("%v %q: %v", ErrBadModule, spec, err)
+		}
+	}
+	return m, nil
+}
+
+func (m *Module) set(key, value string) error {
+	var err error
+	switch key {
+	case "count":
+		m.Count, err = strconv.ParseUint(value, 10, 64)
+		if err == nil && m.Count == 0 {
+			err = ErrBadCount
+		}
+	case "interval":
+		m.Interval, err = time.ParseDuration(value)
+		if err == nil && m.Interval <= 0 {
+			err = ErrBadInterval
+		}
+	case "timeout":
+		m.Timeout, err = time.ParseDuration(value)
+		if err == nil && m.Timeout <= 0 {
+			err = ErrBadInterval
+		}
+	case "size":
+		m.Size, err = strconv.Atoi(value)
+		if err == nil && (m.Size < 0 || m.Size > 65507) {
+			err = fmt.Errorf("size %d out of range", m.Size)
+		}
+	case "protocol":
+		if value != "icmp" && value != "icmp6" {
+			err = ErrBadProtocol
+		}
+		m.Protocol = value
+	default:
+		err = fmt.Errorf("unknown setting %q", key)
+	}
+	return err
+}
+
+// Resolve returns the address of host in the family of m.Protocol.
+func (m *Module) Resolve(host string) *net.IPAddr {
+	if m.Protocol == "icmp6" {
+		return ParseAddr6(host)
+	}
+	return ParseAddr(host)
+}
+
+// Modules maps module names to their configuration.
+// It implements flag.Value so --module can be repeated.
+type Modules map[string]*Module
+
+// NewModules returns the built-in "icmp" module.
+func NewModules() Modules {
+	return Modules{"icmp": NewModule("icmp")}
+}
+
+func (ms Modules) String() string {
+	names := make([]string, 0, len(ms))
+	for name := range ms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// Set adds or replaces a module.
+func (ms Modules) Set(spec string) error {
+	m, err := ParseModule(spec)
+	if err != nil {
+		return err
+	}
+	ms[m.Name] = m
+	return nil
+}
+
+// ParseProbeQuery is the ParseOption of the /probe endpoint,
+// e.g. /probe?target=www.usenix.org&module=icmp
+func ParseProbeQuery(query url.Values, modules Modules) (*net.IPAddr, *Module, error) {
+	host := query.Get("target")
+	if host == "" {
+		return nil, nil, ErrNoTarget
+	}
+	name := query.Get("module")
+	if name == "" {
+		name = "icmp"
+	}
+	m, ok := modules[name]
+	if !ok {
+		return nil, nil, ErrUnknownModule
+	}
+	target := m.Resolve(host)
+	if target == nil {
+		return nil, nil, ErrUnknownHost
+	}
+	return target, m, nil
+}
+
+// NewPayload returns size bytes of echo data.
+func NewPayload(size int) []byte {
+	const quote = "First learn the meaning of what you say, and then speak. "
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = quote[i%len(quote)]
+	}
+	return b
+}
+
+// ProbeResult is the outcome of one /probe request.
+type ProbeResult struct {
+	Duration time.Duration
+	Counter  *Counter
+	Sink     *stats.WelfordSink
+	RTTs     *Quantiles
+	TTL      int
+	// IPProtocol is the IP version, 4 or 6.
+	IPProtocol int
+}
+
+// RunProbe sends m.Count echo requests to target, one every m.Interval.
+func RunProbe(p *Pinger, target *net.IPAddr, m *Module) *ProbeResult {
+	pr := &ProbeResult{Counter: NewCounter(), Sink: stats.NewSink(), RTTs: NewQuantiles(), IPProtocol: 4}
+	if target.IP.To4() == nil {
+		pr.IPProtocol = 6
+	}
+	payload := NewPayload(m.Size)
+	start := time.Now()
+	for i := uint64(0); i < m.Count; i++ {
+		if i > 0 {
+			time.Sleep(m.Interval)
+		}
+		r := p.PingWith(target, payload, m.Timeout)
+		if r.Err != nil && r.Err != ErrTimeout {
+			continue
+		}
+		pr.Counter.OnSent()
+		switch {
+		case r.Type == nil:
+		case IsEchoReply(r.Type):
+			pr.Counter.OnReception()
+			_ = pr.Sink.Push(r.RTT.Seconds())
+			pr.RTTs.Push(r.RTT.Seconds())
+			pr.TTL = r.TTL
+		default:
+			pr.Counter.NoteAnError()
+		}
+	}
+	pr.Duration = time.Since(start)
+	return pr
+}
+
+// Render writes the result in the Prometheus text format
+// using blackbox_exporter's metric names where they exist.
+func (pr *ProbeResult) Render(w io.Writer) {
+	sent, recvd, errs := pr.Counter.totals()
+	success, loss := 0, 0.0
+	if recvd > 0 {
+		success = 1
+	}
+	if sent > 0 {
+		loss = float64(sent-recvd) / float64(sent)
+	}
+	gauge := func(name, help string, value string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, help, name, name, value)
+	}
+	gauge("probe_success", "Whether the probe received a reply.", strconv.Itoa(success))
+	gauge("probe_duration_seconds", "How long the probe took.", formatFloat(pr.Duration.Seconds()))
+	gauge("probe_ip_protocol", "IP protocol version of the probe.", strconv.Itoa(pr.IPProtocol))
+	gauge("probe_packets_sent", "Echo requests sent.", strconv.FormatUint(sent, 10))
+	gauge("probe_packets_received", "Echo replies received.", strconv.FormatUint(recvd, 10))
+	gauge("probe_icmp_errors", "ICMP errors received.", strconv.FormatUint(errs, 10))
+	gauge("probe_loss_ratio", "Fraction of echo requests without a reply.", formatFloat(loss))
+	if recvd > 0 {
+		gauge("probe_icmp_reply_hop_limit", "TTL of the last reply.", strconv.Itoa(pr.TTL))
+		fmt.Fprintf(w, "# HELP probe_rtt_seconds Round trip time statistics.\n# TYPE probe_rtt_seconds gauge\n")
+		type stat struct {
+			stat  string
+			value float64
+		}
+		rtts := []stat{
+			{"min", pr.Sink.Min()},
+			{"avg", pr.Sink.Mean()},
+			{"max", pr.Sink.Max()},
+			{"mdev", pr.Sink.StandardDeviation()},
+		}
+		if pr.RTTs != nil {
+			for _, p := range Percentiles {
+				rtts = append(rtts, stat{fmt.Sprintf("p%v", p), pr.RTTs.Percentile(p)})
+			}
+		}
+		for _, s := range rtts {
+			fmt.Fprintf(w, "probe_rtt_seconds{stat=\"%s\"} %s\n", s.stat, formatFloat(thirdparty.ToFixed(s.value, 9)))
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"github.com/erriapo/stats"
+	"github.com/google/go-cmp/cmp"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseModule(t *testing.T) {
+	m, err := ParseModule("icmp5,count=5,interval=200ms,size=1400")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Module{Name: "icmp5", Count: 5, Interval: 200 * time.Millisecond,
+		Timeout: time.Second, Size: 1400, Protocol: "icmp"}
+	if !cmp.Equal(m, expected) {
+		t.Errorf("expected %+v ; got %+v", expected, m)
+	}
+	if m, err := ParseModule("v6,protocol=icmp6"); err != nil || m.Protocol != "icmp6" {
+		t.Errorf("unexpected %+v %v", m, err)
+	}
+	for _, spec := range []string{"", "x,count=0", "x,size", "x,protocol=tcp", "x,ttl=3", "x,interval=0s", "x,interval=-1s"} {
+		if _, err := ParseModule(spec); err == nil {
+			t.Errorf("ParseModule(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestParseProbeQuery(t *testing.T) {
+	modules := NewModules()
+	query, _ := url.ParseQuery("target=127.0.0.1")
+	target, m, err := ParseProbeQuery(query, modules)
+	if err != nil || m.Name != "icmp" || target.String() != "127.0.0.1" {
+		t.Errorf("unexpected %v %+v %v", target, m, err)
+	}
+	query, _ = url.ParseQuery("target=127.0.0.1&module=tcp")
+	if _, _, err := ParseProbeQuery(query, modules); err != ErrUnknownModule {
+		t.Errorf("expected %v ; got %v", ErrUnknownModule, err)
+	}
+	modules["v6"] = &Module{Name: "v6", Protocol: "icmp6"}
+	query, _ = url.ParseQuery("target=localhost&module=v6")
+	if target, _, err := ParseProbeQuery(query, modules); err != nil || target.String() != "::1" {
+		t.Errorf("expected ::1 ; got %v %v", target, err)
+	}
+	query, _ = url.ParseQuery("target=127.0.0.1&module=v6")
+	if _, _, err := ParseProbeQuery(query, modules); err != ErrUnknownHost {
+		t.Errorf("expected %v ; got %v", ErrUnknownHost, err)
+	}
+	query, _ = url.ParseQuery("target=babihutan")
+	if _, _, err := ParseProbeQuery(query, modules); err != ErrUnknownHost {
+		t.Errorf("expected %v ; got %v", ErrUnknownHost, err)
+	}
+}
+
+func TestProbeResultRender(t *testing.T) {
+	pr := &ProbeResult{Duration: time.Second, Counter: NewCounter(), Sink: stats.NewSink(), TTL: 57}
+	pr.Counter.OnSent()
+	pr.Counter.OnSent()
+	pr.Counter.OnReception()
+	_ = pr.Sink.Push(0.002)
+	var b bytes.Buffer
+	pr.Render(&b)
+	for _, line := range []string{"probe_success 1", "probe_loss_ratio 0.5",
+		"probe_icmp_reply_hop_limit 57", `probe_rtt_seconds{stat="avg"} 0.002`} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("missing <%v> in\n%v", line, b.String())
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"errors"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv6"
+	"net"
+	"time"
+)
+
+// Neighbor Discovery option types (RFC 4861 section 4.6)
+const (
+	ndSourceLinkLayer = 1
+	ndTargetLinkLayer = 2
+)
+
+// protocolIPv6ICMP is the IANA protocol number for ICMPv6.
+const protocolIPv6ICMP = 58
+
+// ErrNotAdvert means the ICMPv6 message is not a usable Neighbor Advertisement.
+var ErrNotAdvert = errors.New("not a neighbor advertisement")
+
+// ErrNoInterface means the mode requires an interface chosen with -I.
+var ErrNoInterface = errors.New("an interface must be chosen with -I")
+
+// SolicitedNodeAddr returns the ff02::1:ffXX:XXXX group of an IPv6 address.
+func SolicitedNodeAddr(ip net.IP) net.IP {
+	ip16 := ip.To16()
+	return net.IP{0xff, 0x02, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0x01, 0xff, ip16[13], ip16[14], ip16[15]}
+}
+
+// NewNeighborSolicitation constructs a Neighbor Solicitation for target
+// advertising mac as our link-layer address.
+// The kernel computes the ICMPv6 checksum.
+func NewNeighborSolicitation(target net.IP, mac net.HardwareAddr) icmp.Message {
+	b := make([]byte, 4+net.IPv6len, 4+net.IPv6len+8)
+	copy(b[4:], target.To16())
+	if len(mac) == 6 {
+		b = append(b, ndSourceLinkLayer, 1)
+		b = append(b, mac...)
+	}
+	return icmp.Message{
+		Type: ipv6.ICMPTypeNeighborSolicitation,
+		Code: 0,
+		Body: &icmp.RawBody{Data: b},
+	}
+}
+
+// NeighborAdvert is a decoded Neighbor Advertisement.
+type NeighborAdvert struct {
+	Target    net.IP
+	MAC       net.HardwareAddr
+	Router    bool
+	Solicited bool
+	Override  bool
+}
+
+// NDResponse is one answer to a Neighbor Solicitation.
+type NDResponse struct {
+	From   net.Addr
+	Advert *NeighborAdvert
+	RTT    time.Duration
+}
+
+// ParseNeighborAdvert decodes the body of a Neighbor Advertisement.
+func ParseNeighborAdvert(m *icmp.Message) (*NeighborAdvert, error) {
+	raw, ok := m.Body.(*icmp.RawBody)
+	if m.Type != ipv6.ICMPTypeNeighborAdvertisement || !ok || len(raw.Data) < 4+net.IPv6len {
+		return nil, ErrNotAdvert
+	}
+	b := raw.Data
+	na := &NeighborAdvert{
+		Router:    b[0]&0x80 != 0,
+		Solicited: b[0]&0x40 != 0,
+		Override:  b[0]&0x20 != 0,
+		Target:    net.IP(append([]byte(nil), b[4:4+net.IPv6len]...)),
+	}
+	for opts := b[4+net.IPv6len:]; len(opts) >= 8; {
+		l := int(opts[1]) * 8
+		if l == 0 || l > len(opts) {
+			break
+		}
+		if opts[0] == ndTargetLinkLayer && l >= 8 {
+			na.MAC = net.HardwareAddr(append([]byte(nil), opts[2:8]...))
+		}
+		opts = opts[l:]
+	}
+	return na, nil
+}
+
+// NDPinger solicits an on-link IPv6 neighbour through one interface.
+type NDPinger struct {
+	c      *icmp.PacketConn
+	ifi    *net.Interface
+	target net.IP
+}
+
+// NewNDPinger opens an ICMPv6 socket that only sees Neighbor Advertisements.
+func NewNDPinger(ifi *net.Interface, target net.IP) (*NDPinger, error) {
+	c, err := icmp.ListenPacket("ip6:ipv6-icmp", "::")
+	if err != nil {
+		return nil, err
+	}
+	p := c.IPv6PacketConn()
+	var f ipv6.ICMPFilter
+	f.SetAll(true)
+	f.Accept(ipv6.ICMPTypeNeighborAdvertisement)
+	// RFC 4861 requires a hop limit of 255 on ND messages
+	for _, err := range []error{
+		p.SetMulticastInterface(ifi),
+		p.SetMulticastHopLimit(255),
+		p.SetHopLimit(255),
+		p.SetICMPFilter(&f),
+	} {
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return &NDPinger{c: c, ifi: ifi, target: target.To16()}, nil
+}
+
+// Ping sends one Neighbor Solicitation and collects every
+// advertisement for the target that arrives within window.
+func (p *NDPinger) Ping(window time.Duration) ([]NDResponse, error) {
+	wm := NewNeighborSolicitation(p.target, p.ifi.HardwareAddr)
+	wb, err := wm.Marshal(nil)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	if err := p.c.SetDeadline(start.Add(window)); err != nil {
+		return nil, err
+	}
+	dst := &net.IPAddr{IP: SolicitedNodeAddr(p.target), Zone: p.ifi.Name}
+	if _, err := p.c.WriteTo(wb, dst); err != nil {
+		return nil, err
+	}
+
+	var responses []NDResponse
+	rb := make([]byte, 1500)
+	for {
+		n, peer, err := p.c.ReadFrom(rb)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				return responses, nil
+			}
+			return responses, err
+		}
+		rm, err := icmp.ParseMessage(protocolIPv6ICMP, rb[:n])
+		if err != nil {
+			continue
+		}
+		na, err := ParseNeighborAdvert(rm)
+		if err != nil || !na.Target.Equal(p.target) {
+			continue
+		}
+		responses = append(responses, NDResponse{From: peer, Advert: na, RTT: time.Since(start)})
+	}
+}
+
+// Close releases the socket.
+func (p *NDPinger) Close() error {
+	return p.c.Close()
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv6"
+	"net"
+	"testing"
+)
+
+func TestSolicitedNodeAddr(t *testing.T) {
+	expected := net.ParseIP("ff02::1:ff28:9c5a")
+	if got := SolicitedNodeAddr(net.ParseIP("fe80::2aa:ff:fe28:9c5a")); !got.Equal(expected) {
+		t.Errorf("expected %v ; got %v", expected, got)
+	}
+}
+
+func TestParseNeighborAdvert(t *testing.T) {
+	target := net.ParseIP("fe80::1")
+	b := make([]byte, 4+16)
+	b[0] = 0x80 | 0x40
+	copy(b[4:], target)
+	b = append(b, ndTargetLinkLayer, 1, 0x02, 0, 0x5e, 0, 0x53, 0x01)
+	na, err := ParseNeighborAdvert(&icmp.Message{Type: ipv6.ICMPTypeNeighborAdvertisement,
+		Body: &icmp.RawBody{Data: b}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !na.Router || !na.Solicited || na.Override || !na.Target.Equal(target) ||
+		na.MAC.String() != "02:00:5e:00:53:01" {
+		t.Errorf("unexpected advert %+v", na)
+	}
+	if _, err := ParseNeighborAdvert(&icmp.Message{Type: ipv6.ICMPTypeEchoReply}); err != ErrNotAdvert {
+		t.Errorf("expected %v ; got %v", ErrNotAdvert, err)
+	}
+}
+
+func TestNeighborDiscoveryNeedsInterface(t *testing.T) {
+	if _, err := ParseArgs([]string{"--nd", "fe80::1"}); err != ErrNoInterface {
+		t.Errorf("expected %v ; got %v", ErrNoInterface, err)
+	}
+	if _, err := ParseArgs([]string{"--nd", "-I", "eth0", "192.0.2.1"}); err != ErrUnknownHost {
+		t.Errorf("expected %v ; got %v", ErrUnknownHost, err)
+	}
+	if _, err := ParseArgs([]string{"fe80::1"}); err != ErrUnknownHost {
+		t.Errorf("expected %v ; got %v", ErrUnknownHost, err)
+	}
+}
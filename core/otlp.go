@@ -0,0 +1,260 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTLP/HTTP with the JSON encoding of the protobuf messages
+// (https://opentelemetry.io/docs/specs/otlp/#otlphttp).
+// 64 bit integers are strings in that encoding.
+
+const otlpCumulative = 2
+
+// formatOTLP is not a --format; see --otlp.
+const formatOTLP = "otlp"
+
+// NewOTLPOutput returns an Output that accumulates the probe outcomes
+// & exports them as cumulative OTLP metrics on every Report & Finish.
+func NewOTLPOutput(w io.Writer) *Output {
+	return &Output{format: formatOTLP, w: w, otlp: newOTLPState()}
+}
+
+// OpenOTLP returns a writer POSTing each export, in the
+// background, to the OTLP/HTTP collector at endpoint.
+func OpenOTLP(endpoint string) io.WriteCloser {
+	return newHTTPWriter(OTLPEndpoint(endpoint), "application/json", false)
+}
+
+// OTLPEndpoint completes a collector address, e.g.
+// http://localhost:4318, with the metrics path.
+func OTLPEndpoint(endpoint string) string {
+	if strings.HasSuffix(endpoint, "/v1/metrics") {
+		return endpoint
+	}
+	return strings.TrimSuffix(endpoint, "/") + "/v1/metrics"
+}
+
+// otlpState accumulates cumulative metrics between exports.
+// Losses are counted as probes time out or draw an ICMP error,
+// so that the counter never drops while a probe is in flight.
+type otlpState struct {
+	start    time.Time
+	sent     uint64
+	received uint64
+	lost     uint64
+	repeats  Repeats
+	errors   map[icmpError]uint64
+	buckets  []uint64
+	count    uint64
+	sum      float64
+	min      float64
+	max      float64
+	rtts     *Quantiles
+}
+
+func newOTLPState() *otlpState {
+	return &otlpState{
+		start:   time.Now(),
+		errors:  make(map[icmpError]uint64),
+		buckets: make([]uint64, len(RTTBuckets)+1),
+		rtts:    NewQuantiles(),
+	}
+}
+
+func (s *otlpState) observe(e Event) {
+	if s.repeats.Repeated(e) {
+		return
+	}
+	if lost, ok := Lost(e); ok && lost {
+		s.lost++
+	}
+	switch e.Type {
+	case EventSent:
+		s.sent++
+	case EventReply:
+		s.received++
+		rtt := time.Duration(e.RTT).Seconds()
+		i := 0
+		for i < len(RTTBuckets) && rtt > RTTBuckets[i] {
+			i++
+		}
+		s.buckets[i]++
+		if s.count == 0 || rtt < s.min {
+			s.min = rtt
+		}
+		if s.count == 0 || rtt > s.max {
+			s.max = rtt
+		}
+		s.count++
+		s.sum += rtt
+		s.rtts.Push(rtt)
+	case EventError:
+		s.errors[icmpError{e.ICMPType, e.ICMPCode}]++
+	}
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpNumberPoint struct {
+	Attributes []otlpAttribute `json:"attributes,omitempty"`
+	StartTime  string          `json:"startTimeUnixNano"`
+	Time       string          `json:"timeUnixNano"`
+	AsInt      string          `json:"asInt"`
+}
+
+type otlpHistogramPoint struct {
+	Attributes     []otlpAttribute `json:"attributes,omitempty"`
+	StartTime      string          `json:"startTimeUnixNano"`
+	Time           string          `json:"timeUnixNano"`
+	Count          string          `json:"count"`
+	Sum            float64         `json:"sum"`
+	BucketCounts   []string        `json:"bucketCounts"`
+	ExplicitBounds []float64       `json:"explicitBounds"`
+	Min            *float64        `json:"min,omitempty"`
+	Max            *float64        `json:"max,omitempty"`
+}
+
+type otlpQuantile struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+type otlpSummaryPoint struct {
+	Attributes     []otlpAttribute `json:"attributes,omitempty"`
+	StartTime      string          `json:"startTimeUnixNano"`
+	Time           string          `json:"timeUnixNano"`
+	Count          string          `json:"count"`
+	Sum            float64         `json:"sum"`
+	QuantileValues []otlpQuantile  `json:"quantileValues"`
+}
+
+type otlpSummary struct {
+	DataPoints []otlpSummaryPoint `json:"dataPoints"`
+}
+
+type otlpSum struct {
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+	DataPoints             []otlpNumberPoint `json:"dataPoints"`
+}
+
+type otlpHistogram struct {
+	AggregationTemporality int                  `json:"aggregationTemporality"`
+	DataPoints             []otlpHistogramPoint `json:"dataPoints"`
+}
+
+type otlpMetric struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Unit        string         `json:"unit"`
+	Sum         *otlpSum       `json:"sum,omitempty"`
+	Histogram   *otlpHistogram `json:"histogram,omitempty"`
+	Summary     *otlpSummary   `json:"summary,omitempty"`
+}
+
+type otlpScopeMetrics struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+func attributes(pairs ...string) []otlpAttribute {
+	var attrs []otlpAttribute
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			attrs = append(attrs, otlpAttribute{Key: pairs[i], Value: otlpValue{StringValue: pairs[i+1]}})
+		}
+	}
+	return attrs
+}
+
+func formatUint(u uint64) string {
+	return strconv.FormatUint(u, 10)
+}
+
+// marshal renders the accumulated metrics as an
+// ExportMetricsServiceRequest.
+func (s *otlpState) marshal(t Tags, now time.Time) ([]byte, error) {
+	hostname, _ := os.Hostname()
+	target := attributes("server.address", t.Target, "network.peer.address", t.IP, "network.protocol.name", t.Protocol)
+	start, end := strconv.FormatInt(s.start.UnixNano(), 10), strconv.FormatInt(now.UnixNano(), 10)
+	counter := func(name, description, unit string, attrs []otlpAttribute, value uint64) otlpMetric {
+		return otlpMetric{Name: name, Description: description, Unit: unit,
+			Sum: &otlpSum{AggregationTemporality: otlpCumulative, IsMonotonic: true,
+				DataPoints: []otlpNumberPoint{{Attributes: attrs, StartTime: start, Time: end, AsInt: formatUint(value)}}}}
+	}
+
+	metrics := []otlpMetric{
+		counter("goping.probes.sent", "ICMP echo requests sent.", "{probe}", target, s.sent),
+		counter("goping.probes.received", "ICMP echo replies received.", "{probe}", target, s.received),
+		counter("goping.probes.lost", "Echo requests that timed out or drew an ICMP error.", "{probe}", target, s.lost),
+	}
+
+	errors := counter("goping.icmp.errors", "ICMP errors received in response to probes.", "{error}", nil, 0)
+	errors.Sum.DataPoints = nil
+	for k, v := range s.errors {
+		attrs := append(append([]otlpAttribute(nil), target...),
+			attributes("icmp.type", strconv.Itoa(k.typ), "icmp.code", strconv.Itoa(k.code))...)
+		errors.Sum.DataPoints = append(errors.Sum.DataPoints,
+			otlpNumberPoint{Attributes: attrs, StartTime: start, Time: end, AsInt: formatUint(v)})
+	}
+	if len(errors.Sum.DataPoints) > 0 {
+		metrics = append(metrics, errors)
+	}
+
+	point := otlpHistogramPoint{Attributes: target, StartTime: start, Time: end,
+		Count: formatUint(s.count), Sum: s.sum, ExplicitBounds: RTTBuckets}
+	for _, b := range s.buckets {
+		point.BucketCounts = append(point.BucketCounts, formatUint(b))
+	}
+	if s.count > 0 {
+		min, max := s.min, s.max
+		point.Min, point.Max = &min, &max
+	}
+	metrics = append(metrics, otlpMetric{Name: "goping.rtt", Description: "Round trip time of echo replies.", Unit: "s",
+		Histogram: &otlpHistogram{AggregationTemporality: otlpCumulative, DataPoints: []otlpHistogramPoint{point}}})
+	if s.count > 0 {
+		summary := otlpSummaryPoint{Attributes: target, StartTime: start, Time: end, Count: formatUint(s.count), Sum: s.sum}
+		for _, p := range Percentiles {
+			summary.QuantileValues = append(summary.QuantileValues, otlpQuantile{Quantile: quantile(p), Value: s.rtts.Percentile(p)})
+		}
+		metrics = append(metrics, otlpMetric{Name: "goping.rtt.percentiles", Description: "Percentiles of the round trip time of echo replies.", Unit: "s",
+			Summary: &otlpSummary{DataPoints: []otlpSummaryPoint{summary}}})
+	}
+
+	var rm otlpResourceMetrics
+	rm.Resource.Attributes = attributes("service.name", "goping", "host.name", hostname, "network.interface.name", t.Interface)
+	var sm otlpScopeMetrics
+	sm.Scope.Name = "github.com/erriapo/goping"
+	sm.Metrics = metrics
+	rm.ScopeMetrics = []otlpScopeMetrics{sm}
+	return json.Marshal(&otlpRequest{ResourceMetrics: []otlpResourceMetrics{rm}})
+}
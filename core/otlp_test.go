@@ -0,0 +1,94 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOTLPReceiver(t *testing.T) {
+	requests := make(chan otlpRequest, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpRequest
+		if r.URL.Path != "/v1/metrics" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected %v %v", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		requests <- req
+	}))
+	defer receiver.Close()
+
+	o := NewOTLPOutput(OpenOTLP(receiver.URL))
+	o.SetTags(Tags{Target: "www.usenix.org", IP: "192.0.2.1", Interface: "eth0", Protocol: "icmp"})
+	o.Emit(Event{Type: EventSent, Seq: 1})
+	o.Emit(Event{Type: EventReply, Seq: 1, RTT: 3000000})
+	o.Emit(Event{Type: EventReply, Seq: 1, RTT: 4000000}) // a duplicate
+	o.Emit(Event{Type: EventSent, Seq: 2})
+	o.Emit(Event{Type: EventError, Seq: 2, ICMPType: 3, ICMPCode: 1})
+	o.Finish(&Summary{})
+
+	req := <-requests
+	if len(req.ResourceMetrics) != 1 || len(req.ResourceMetrics[0].ScopeMetrics) != 1 {
+		t.Fatalf("unexpected request %+v", req)
+	}
+	resource := map[string]string{}
+	for _, a := range req.ResourceMetrics[0].Resource.Attributes {
+		resource[a.Key] = a.Value.StringValue
+	}
+	if resource["service.name"] != "goping" || resource["network.interface.name"] != "eth0" || resource["host.name"] == "" {
+		t.Errorf("unexpected resource %v", resource)
+	}
+	metrics := map[string]otlpMetric{}
+	for _, m := range req.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+	for name, expected := range map[string]string{
+		"goping.probes.sent": "2", "goping.probes.received": "1",
+		"goping.probes.lost": "1", "goping.icmp.errors": "1",
+	} {
+		m, ok := metrics[name]
+		if !ok || m.Sum == nil || !m.Sum.IsMonotonic || m.Sum.DataPoints[0].AsInt != expected {
+			t.Errorf("expected %v %v ; got %+v", name, expected, m)
+		}
+	}
+	if p := metrics["goping.rtt.percentiles"]; p.Summary == nil || p.Summary.DataPoints[0].QuantileValues[4].Quantile != 0.999 {
+		t.Errorf("unexpected goping.rtt.percentiles %+v", p)
+	}
+	rtt := metrics["goping.rtt"]
+	if rtt.Unit != "s" || rtt.Histogram == nil {
+		t.Fatalf("unexpected goping.rtt %+v", rtt)
+	}
+	point := rtt.Histogram.DataPoints[0]
+	// 3ms falls in (0.0025, 0.005]
+	if point.Count != "1" || point.BucketCounts[3] != "1" || len(point.BucketCounts) != len(RTTBuckets)+1 || *point.Max != 0.003 {
+		t.Errorf("unexpected histogram %+v", point)
+	}
+}
+
+func TestOTLPLostNeverDrops(t *testing.T) {
+	s := newOTLPState()
+	for _, c := range []struct {
+		e    Event
+		lost uint64
+	}{
+		// a probe in flight is not lost yet
+		{Event{Type: EventSent, Seq: 1}, 0},
+		{Event{Type: EventReply, Seq: 1}, 0},
+		{Event{Type: EventSent, Seq: 2}, 0},
+		{Event{Type: EventTimeout, Seq: 2}, 1},
+		{Event{Type: EventSent, Seq: 3}, 1},
+		{Event{Type: EventError, Seq: 3}, 2},
+	} {
+		s.observe(c.e)
+		if s.lost != c.lost {
+			t.Errorf("after %+v: expected %d lost ; got %d", c.e, c.lost, s.lost)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Outage is a period during which the target was down. It starts with
+// the first of the losses that brought the target down & ends with the
+// first of the replies that brought it back up. End is nil while the
+// outage goes on.
+type Outage struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"`
+	Lost  uint64     `json:"lost"`
+}
+
+// Duration returns how long the outage lasted, or has lasted until now.
+func (o Outage) Duration(now time.Time) time.Duration {
+	if o.End == nil {
+		return now.Sub(o.Start)
+	}
+	return o.End.Sub(o.Start)
+}
+
+// OutageLog is the up/down state machine of a target: down after
+// downAfter consecutive losses & up again after upAfter consecutive
+// replies. It logs every transition & is safe for concurrent use.
+type OutageLog struct {
+	lock      sync.Mutex
+	target    string
+	downAfter int
+	upAfter   int
+	log       io.Writer
+	have      bool
+	first     time.Time
+	down      bool
+	losses    int
+	replies   int
+	runStart  time.Time
+	outages   []Outage
+}
+
+// NewOutageLog logs the transitions of target to w.
+func NewOutageLog(target string, downAfter, upAfter int, w io.Writer) *OutageLog {
+	return &OutageLog{target: target, downAfter: downAfter, upAfter: upAfter, log: w}
+}
+
+// Observe advances the state machine with the outcome of a probe,
+// once per probe; see Repeats.
+func (o *OutageLog) Observe(e Event) {
+	lost, ok := Lost(e)
+	if !ok {
+		return
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if !o.have {
+		o.first = e.Time
+	}
+	o.have = true
+
+	if lost {
+		if o.losses == 0 {
+			o.runStart = e.Time
+		}
+		o.losses++
+		o.replies = 0
+		if o.down {
+			o.outages[len(o.outages)-1].Lost++
+		} else if o.losses >= o.downAfter {
+			o.down = true
+			o.outages = append(o.outages, Outage{Start: o.runStart, Lost: uint64(o.losses)})
+			fmt.Fprintf(o.log, "%s %s is DOWN after %d lost probes, since %s\n",
+				e.Time.Format(time.RFC3339), o.target, o.losses, o.runStart.Format(time.RFC3339))
+		}
+		return
+	}
+
+	if o.replies == 0 {
+		o.runStart = e.Time
+	}
+	o.replies++
+	o.losses = 0
+	if o.down && o.replies >= o.upAfter {
+		o.down = false
+		current := &o.outages[len(o.outages)-1]
+		end := o.runStart
+		current.End = &end
+		fmt.Fprintf(o.log, "%s %s is UP after %d replies, outage lasted %v, %v down in total\n",
+			e.Time.Format(time.RFC3339), o.target, o.replies, current.Duration(e.Time), o.downtime(e.Time))
+	}
+}
+
+func (o *OutageLog) downtime(now time.Time) time.Duration {
+	var d time.Duration
+	for _, outage := range o.outages {
+		d += outage.Duration(now)
+	}
+	return d
+}
+
+// Outages returns the outages so far.
+func (o *OutageLog) Outages() []Outage {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return append([]Outage(nil), o.outages...)
+}
+
+// Availability returns the percentage of the time since the first
+// probe outcome that the target was up.
+func (o *OutageLog) Availability(now time.Time) float64 {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	total := now.Sub(o.first)
+	if !o.have || total <= 0 {
+		return 100
+	}
+	return 100 * (1 - float64(o.downtime(now))/float64(total))
+}
+
+// SetOutages adds the outages & availability of l as of now.
+func (s *Summary) SetOutages(l *OutageLog, now time.Time) {
+	s.Outages = l.Outages()
+	s.Availability = l.Availability(now)
+}
+
+// RenderOutages writes the outages of s, one per line, after
+// their count & the availability. It writes nothing without outages.
+func RenderOutages(w io.Writer, s *Summary, now time.Time) {
+	if len(s.Outages) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%d outages, %.3f%% availability\n", len(s.Outages), s.Availability)
+	for i, outage := range s.Outages {
+		end := "ongoing"
+		if outage.End != nil {
+			end = outage.End.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "  outage %d: %s - %s, %v, %d probes lost\n",
+			i+1, outage.Start.Format(time.RFC3339), end, outage.Duration(now), outage.Lost)
+	}
+}
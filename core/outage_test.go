@@ -0,0 +1,57 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestOutageLog(t *testing.T) {
+	var log bytes.Buffer
+	o := NewOutageLog("www.usenix.org", 2, 2, &log)
+	start := time.Unix(1500000000, 0).UTC()
+	at := func(i int) time.Time {
+		return start.Add(time.Duration(i) * time.Second)
+	}
+	// a single loss is no outage; 3 losses are, until 2 replies in a row
+	for i, c := range "RLRLLLRLRRRLL" {
+		e := Event{Type: EventReply, Seq: i + 1, Time: at(i)}
+		if c == 'L' {
+			e.Type = EventTimeout
+		}
+		o.Observe(e)
+	}
+	expected := "2017-07-14T02:40:04Z www.usenix.org is DOWN after 2 lost probes, since 2017-07-14T02:40:03Z\n" +
+		"2017-07-14T02:40:09Z www.usenix.org is UP after 2 replies, outage lasted 5s, 5s down in total\n" +
+		"2017-07-14T02:40:12Z www.usenix.org is DOWN after 2 lost probes, since 2017-07-14T02:40:11Z\n"
+	if log.String() != expected {
+		t.Errorf("expected <%v> ; got <%v>", expected, log.String())
+	}
+	outages := o.Outages()
+	if len(outages) != 2 || outages[0].Lost != 4 || !outages[0].End.Equal(at(8)) || outages[1].End != nil {
+		t.Fatalf("unexpected outages %+v", outages)
+	}
+
+	var s Summary
+	s.SetOutages(o, at(20))
+	// down from 3s to 8s & since 11s, out of 20s
+	if math.Abs(s.Availability-30) > 1e-9 {
+		t.Errorf("expected 30%% availability ; got %v", s.Availability)
+	}
+	var b bytes.Buffer
+	RenderOutages(&b, &s, at(20))
+	expected = "2 outages, 30.000% availability\n" +
+		"  outage 1: 2017-07-14T02:40:03Z - 2017-07-14T02:40:08Z, 5s, 4 probes lost\n" +
+		"  outage 2: 2017-07-14T02:40:11Z - ongoing, 9s, 2 probes lost\n"
+	if b.String() != expected {
+		t.Errorf("expected <%v> ; got <%v>", expected, b.String())
+	}
+	if _, err := ParseArgs([]string{"--down-after", "0", "localhost"}); err != ErrBadOutage {
+		t.Errorf("expected %v ; got %v", ErrBadOutage, err)
+	}
+}
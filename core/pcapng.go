@@ -0,0 +1,159 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// pcapng block types & options,
+// see https://www.ietf.org/archive/id/draft-ietf-opsawg-pcapng-02.html
+const (
+	pcapngSectionHeader       = 0x0a0d0d0a
+	pcapngInterface           = 0x00000001
+	pcapngEnhancedPacket      = 0x00000006
+	pcapngByteOrderMagic      = 0x1a2b3c4d
+	pcapngOptEnd              = 0
+	pcapngOptComment          = 1
+	pcapngOptUserAppl         = 4
+	pcapngOptTsResol          = 9
+	pcapngOptFlags            = 2
+	pcapngFlagInbound         = 1
+	pcapngFlagOutbound        = 2
+	linkTypeIPv4              = 228
+	pcapngNanoseconds    byte = 9
+)
+
+// PcapWriter writes packets to a pcapng file with a single
+// interface carrying raw IPv4, so Wireshark needs no link layer.
+type PcapWriter struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+// NewPcapWriter writes the section header & interface description.
+func NewPcapWriter(w io.Writer) (*PcapWriter, error) {
+	p := &PcapWriter{w: w}
+
+	var shb bytes.Buffer
+	le := binary.LittleEndian
+	_ = binary.Write(&shb, le, uint32(pcapngByteOrderMagic))
+	_ = binary.Write(&shb, le, uint16(1)) // major
+	_ = binary.Write(&shb, le, uint16(0)) // minor
+	_ = binary.Write(&shb, le, int64(-1)) // section length unknown
+	pcapngOption(&shb, pcapngOptUserAppl, []byte("goping"))
+	pcapngOption(&shb, pcapngOptEnd, nil)
+	if err := p.block(pcapngSectionHeader, shb.Bytes()); err != nil {
+		return nil, err
+	}
+
+	var idb bytes.Buffer
+	_ = binary.Write(&idb, le, uint16(linkTypeIPv4))
+	_ = binary.Write(&idb, le, uint16(0))
+	_ = binary.Write(&idb, le, uint32(0)) // no snap length
+	pcapngOption(&idb, pcapngOptTsResol, []byte{pcapngNanoseconds})
+	pcapngOption(&idb, pcapngOptEnd, nil)
+	if err := p.block(pcapngInterface, idb.Bytes()); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WritePacket records an IPv4 packet seen at t. The comment,
+// when not empty, shows up in Wireshark's packet comments.
+func (p *PcapWriter) WritePacket(t time.Time, packet []byte, outbound bool, comment string) error {
+	var epb bytes.Buffer
+	le := binary.LittleEndian
+	ts := uint64(t.UnixNano())
+	_ = binary.Write(&epb, le, uint32(0)) // interface
+	_ = binary.Write(&epb, le, uint32(ts>>32))
+	_ = binary.Write(&epb, le, uint32(ts))
+	_ = binary.Write(&epb, le, uint32(len(packet)))
+	_ = binary.Write(&epb, le, uint32(len(packet)))
+	epb.Write(packet)
+	epb.Write(make([]byte, pad4(len(packet))))
+	if comment != "" {
+		pcapngOption(&epb, pcapngOptComment, []byte(comment))
+	}
+	flags := make([]byte, 4)
+	if outbound {
+		le.PutUint32(flags, pcapngFlagOutbound)
+	} else {
+		le.PutUint32(flags, pcapngFlagInbound)
+	}
+	pcapngOption(&epb, pcapngOptFlags, flags)
+	pcapngOption(&epb, pcapngOptEnd, nil)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.block(pcapngEnhancedPacket, epb.Bytes())
+}
+
+// WriteICMP records an ICMP message read from or written to an
+// icmp.PacketConn, which hides the IPv4 header, behind a rebuilt one.
+func (p *PcapWriter) WriteICMP(t time.Time, src, dst net.IP, ttl int, message []byte, outbound bool, comment string) error {
+	h := NewIPv4Header(dst, nil, len(message))
+	h.Src = src
+	if ttl > 0 {
+		h.TTL = ttl
+	}
+	b, err := h.Marshal()
+	if err != nil {
+		return err
+	}
+	// ipv4.Header.Marshal writes TotalLen in host byte order on some platforms
+	binary.BigEndian.PutUint16(b[2:4], uint16(h.TotalLen))
+	binary.BigEndian.PutUint16(b[10:12], internetChecksum(b))
+	return p.WritePacket(t, append(b, message...), outbound, comment)
+}
+
+func (p *PcapWriter) block(typ uint32, body []byte) error {
+	length := uint32(12 + len(body))
+	b := make([]byte, 0, length)
+	b = appendUint32(b, typ)
+	b = appendUint32(b, length)
+	b = append(b, body...)
+	b = appendUint32(b, length)
+	_, err := p.w.Write(b)
+	return err
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var u [4]byte
+	binary.LittleEndian.PutUint32(u[:], v)
+	return append(b, u[:]...)
+}
+
+func pcapngOption(b *bytes.Buffer, code uint16, value []byte) {
+	_ = binary.Write(b, binary.LittleEndian, code)
+	_ = binary.Write(b, binary.LittleEndian, uint16(len(value)))
+	b.Write(value)
+	b.Write(make([]byte, pad4(len(value))))
+}
+
+// pad4 is the padding needed to align n bytes to 32 bits.
+func pad4(n int) int {
+	return (4 - n%4) % 4
+}
+
+// internetChecksum is the RFC 1071 checksum of b.
+func internetChecksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
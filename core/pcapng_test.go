@@ -0,0 +1,74 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/net/icmp"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestPcapngBlocks(t *testing.T) {
+	var b bytes.Buffer
+	p, err := NewPcapWriter(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wm := NewEcho("hello", 7)
+	wb, _ := wm.Marshal(nil)
+	when := time.Unix(1500000000, 123456789)
+	if err := p.WriteICMP(when, net.IPv4(192, 0, 2, 2), net.IPv4(192, 0, 2, 1), 64, wb, true, "probe icmp_seq=7"); err != nil {
+		t.Fatal(err)
+	}
+
+	var types []uint32
+	var epb []byte
+	for rest := b.Bytes(); len(rest) > 0; {
+		typ, length := binary.LittleEndian.Uint32(rest), binary.LittleEndian.Uint32(rest[4:])
+		if length%4 != 0 || binary.LittleEndian.Uint32(rest[length-4:]) != length {
+			t.Fatalf("bad block length %d", length)
+		}
+		types = append(types, typ)
+		if typ == pcapngEnhancedPacket {
+			epb = rest[8 : length-4]
+		}
+		rest = rest[length:]
+	}
+	if !cmp.Equal(types, []uint32{pcapngSectionHeader, pcapngInterface, pcapngEnhancedPacket}) {
+		t.Fatalf("unexpected blocks %x", types)
+	}
+	ts := uint64(binary.LittleEndian.Uint32(epb[4:]))<<32 | uint64(binary.LittleEndian.Uint32(epb[8:]))
+	if ts != uint64(when.UnixNano()) {
+		t.Errorf("expected timestamp %d ; got %d", when.UnixNano(), ts)
+	}
+	captured := int(binary.LittleEndian.Uint32(epb[12:]))
+	packet := epb[20 : 20+captured]
+	h, err := icmp.ParseIPv4Header(packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.Src.Equal(net.IPv4(192, 0, 2, 2)) || !h.Dst.Equal(net.IPv4(192, 0, 2, 1)) || internetChecksum(packet[:h.Len]) != 0 {
+		t.Errorf("unexpected header %v", h)
+	}
+	if !bytes.Equal(packet[h.Len:], wb) {
+		t.Errorf("expected message %x ; got %x", wb, packet[h.Len:])
+	}
+	if !bytes.Contains(epb[20+captured:], []byte("probe icmp_seq=7")) {
+		t.Errorf("missing comment")
+	}
+}
+
+func TestPcapModes(t *testing.T) {
+	for _, mode := range [][]string{{"--arp", "-I", "eth0"}, {"--nd", "-I", "eth0"}, {"-b"}} {
+		args := append(append([]string{"--pcap", "probes.pcapng"}, mode...), "127.0.0.1")
+		if _, err := ParseArgs(args); err != ErrPcapMode {
+			t.Errorf("%v: expected %v ; got %v", mode, ErrPcapMode, err)
+		}
+	}
+}
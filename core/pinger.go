@@ -0,0 +1,241 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrTimeout means no reply arrived before the deadline.
+var ErrTimeout = errors.New("no response")
+
+// Result is the outcome of one echo request.
+// Type is nil on timeout or when sending failed.
+type Result struct {
+	Seq  int
+	Peer net.Addr
+	TTL  int
+	RTT  time.Duration
+	Type icmp.Type
+	Code int
+	Err  error
+}
+
+// Pinger sends echo requests over one socket and hands each
+// reply, or ICMP error quoting the request, to whoever sent it.
+// It is safe for concurrent use by many targets.
+type Pinger struct {
+	c       *icmp.PacketConn
+	proto   int
+	echo    icmp.Type
+	id      int
+	payload []byte
+	lock    sync.Mutex
+	seq     int
+	waiting map[int]chan Result
+}
+
+// NewPinger listens for ICMP on address, e.g. "0.0.0.0".
+func NewPinger(address string, payload string) (*Pinger, error) {
+	c, err := icmp.ListenPacket("ip4:icmp", address)
+	if err != nil {
+		return nil, err
+	}
+	// not every platform reports the TTL; it is then left out
+	_ = c.IPv4PacketConn().SetControlMessage(ipv4.FlagTTL, true)
+	return newPinger(c, 1, ipv4.ICMPTypeEcho, payload), nil
+}
+
+// NewPinger6 listens for ICMPv6 on address, e.g. "::".
+func NewPinger6(address string, payload string) (*Pinger, error) {
+	c, err := icmp.ListenPacket("ip6:ipv6-icmp", address)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.IPv6PacketConn().SetControlMessage(ipv6.FlagHopLimit, true)
+	return newPinger(c, protocolIPv6ICMP, ipv6.ICMPTypeEchoRequest, payload), nil
+}
+
+func newPinger(c *icmp.PacketConn, proto int, echo icmp.Type, payload string) *Pinger {
+	p := &Pinger{
+		c:       c,
+		proto:   proto,
+		echo:    echo,
+		id:      os.Getpid() & 0xffff,
+		payload: []byte(payload),
+		waiting: make(map[int]chan Result),
+	}
+	go p.receive()
+	return p
+}
+
+// Ping sends one echo request to dst & waits up to timeout.
+func (p *Pinger) Ping(dst *net.IPAddr, timeout time.Duration) Result {
+	return p.PingWith(dst, p.payload, timeout)
+}
+
+// PingWith is Ping with a different echo payload.
+func (p *Pinger) PingWith(dst *net.IPAddr, payload []byte, timeout time.Duration) Result {
+	p.lock.Lock()
+	p.seq = (p.seq + 1) & 0xffff
+	seq := p.seq
+	ch := make(chan Result, 1)
+	p.waiting[seq] = ch
+	p.lock.Unlock()
+
+	defer func() {
+		p.lock.Lock()
+		delete(p.waiting, seq)
+		p.lock.Unlock()
+	}()
+
+	wm := icmp.Message{
+		Type: p.echo,
+		Code: 0,
+		Body: &icmp.Echo{ID: p.id, Seq: seq, Data: payload},
+	}
+	wb, err := wm.Marshal(nil)
+	if err != nil {
+		return Result{Seq: seq, Err: err}
+	}
+	start := time.Now()
+	if _, err := p.c.WriteTo(wb, dst); err != nil {
+		return Result{Seq: seq, Err: err}
+	}
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case r := <-ch:
+		r.RTT = time.Since(start)
+		return r
+	case <-t.C:
+		return Result{Seq: seq, Err: ErrTimeout}
+	}
+}
+
+// Close stops the Pinger.
+func (p *Pinger) Close() error {
+	return p.c.Close()
+}
+
+// read returns the next packet with its TTL or hop limit, 0 when unknown.
+func (p *Pinger) read(rb []byte) (n int, ttl int, peer net.Addr, err error) {
+	if p.proto == protocolIPv6ICMP {
+		var cm *ipv6.ControlMessage
+		n, cm, peer, err = p.c.IPv6PacketConn().ReadFrom(rb)
+		if cm != nil {
+			ttl = cm.HopLimit
+		}
+		return n, ttl, peer, err
+	}
+	var cm *ipv4.ControlMessage
+	n, cm, peer, err = p.c.IPv4PacketConn().ReadFrom(rb)
+	if cm != nil {
+		ttl = cm.TTL
+	}
+	return n, ttl, peer, err
+}
+
+func (p *Pinger) receive() {
+	rb := make([]byte, 1500)
+	for {
+		n, ttl, peer, err := p.read(rb)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				continue
+			}
+			return
+		}
+		rm, err := icmp.ParseMessage(p.proto, rb[:n])
+		if err != nil {
+			continue
+		}
+		seq, ok := p.match(rm)
+		if !ok {
+			continue
+		}
+		r := Result{Seq: seq, Peer: peer, TTL: ttl, Type: rm.Type, Code: rm.Code}
+		p.lock.Lock()
+		ch, ok := p.waiting[seq]
+		p.lock.Unlock()
+		if ok {
+			select {
+			case ch <- r:
+			default:
+				// duplicate
+			}
+		}
+	}
+}
+
+// match returns the sequence number of our request that rm answers.
+func (p *Pinger) match(rm *icmp.Message) (int, bool) {
+	id, seq, _, ok := MatchReply(rm)
+	if !ok || id != p.id {
+		return 0, false
+	}
+	return seq, true
+}
+
+// IsEchoReply tells whether typ is an ICMP or ICMPv6 echo reply.
+func IsEchoReply(typ icmp.Type) bool {
+	return typ == ipv4.ICMPTypeEchoReply || typ == ipv6.ICMPTypeEchoReply
+}
+
+// MatchReply returns the identifier & sequence number of the echo request
+// that rm answers. ICMP errors quote the IP header & first 8 bytes of the
+// request, whose destination is returned as dst; it is nil for echo replies.
+// ICMPv6 errors are matched when the echo request follows the IPv6 header.
+// Extended echo requests & replies are matched alike.
+func MatchReply(rm *icmp.Message) (id, seq int, dst net.IP, ok bool) {
+	var quoted []byte
+	switch body := rm.Body.(type) {
+	case *icmp.Echo:
+		if !IsEchoReply(rm.Type) {
+			return 0, 0, nil, false
+		}
+		return body.ID, body.Seq, nil, true
+	case *icmp.ExtendedEchoReply:
+		return body.ID, body.Seq, nil, true
+	case *icmp.DstUnreach:
+		quoted = body.Data
+	case *icmp.TimeExceeded:
+		quoted = body.Data
+	case *icmp.ParamProb:
+		quoted = body.Data
+	case *icmp.PacketTooBig:
+		quoted = body.Data
+	default:
+		return 0, 0, nil, false
+	}
+	if len(quoted) < ipv4.HeaderLen {
+		return 0, 0, nil, false
+	}
+	if quoted[0]>>4 == 6 {
+		hl := ipv6.HeaderLen
+		if len(quoted) < hl+8 || quoted[6] != protocolIPv6ICMP || !IsEchoRequest(ipv6.ICMPType(quoted[hl])) {
+			return 0, 0, nil, false
+		}
+		id = int(binary.BigEndian.Uint16(quoted[hl+4 : hl+6]))
+		seq = int(binary.BigEndian.Uint16(quoted[hl+6 : hl+8]))
+		return id, seq, net.IP(quoted[24:40]), true
+	}
+	hl := int(quoted[0]&0x0f) << 2
+	if len(quoted) < hl+8 || !IsEchoRequest(ipv4.ICMPType(quoted[hl])) {
+		return 0, 0, nil, false
+	}
+	id = int(binary.BigEndian.Uint16(quoted[hl+4 : hl+6]))
+	seq = int(binary.BigEndian.Uint16(quoted[hl+6 : hl+8]))
+	return id, seq, net.IP(quoted[16:20]), true
+}
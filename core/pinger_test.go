@@ -0,0 +1,48 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"net"
+	"testing"
+)
+
+func TestPingerMatchesQuotedRequest(t *testing.T) {
+	p := &Pinger{id: 0x1234}
+	request, _ := (&icmp.Message{Type: ipv4.ICMPTypeEcho,
+		Body: &icmp.Echo{ID: 0x1234, Seq: 42, Data: []byte("x")}}).Marshal(nil)
+	quoted := append(make([]byte, ipv4.HeaderLen), request...)
+	quoted[0] = 0x45
+	seq, ok := p.match(&icmp.Message{Type: ipv4.ICMPTypeTimeExceeded, Body: &icmp.TimeExceeded{Data: quoted}})
+	if !ok || seq != 42 {
+		t.Errorf("expected seq 42 ; got %v %v", seq, ok)
+	}
+	if _, ok := p.match(&icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 1, Seq: 42}}); ok {
+		t.Errorf("matched a reply meant for another process")
+	}
+}
+
+func TestMatchReply6(t *testing.T) {
+	request, _ := (&icmp.Message{Type: ipv6.ICMPTypeEchoRequest,
+		Body: &icmp.Echo{ID: 0x1234, Seq: 43, Data: []byte("x")}}).Marshal(nil)
+	quoted := append(make([]byte, ipv6.HeaderLen), request...)
+	quoted[0] = 0x60
+	quoted[6] = protocolIPv6ICMP
+	copy(quoted[24:40], net.ParseIP("2001:db8::1"))
+	id, seq, dst, ok := MatchReply(&icmp.Message{Type: ipv6.ICMPTypeTimeExceeded, Body: &icmp.TimeExceeded{Data: quoted}})
+	if !ok || id != 0x1234 || seq != 43 || !dst.Equal(net.ParseIP("2001:db8::1")) {
+		t.Errorf("expected 0x1234 43 2001:db8::1 ; got %#x %v %v %v", id, seq, dst, ok)
+	}
+	id, seq, _, ok = MatchReply(&icmp.Message{Type: ipv6.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 0x1234, Seq: 44}})
+	if !ok || id != 0x1234 || seq != 44 {
+		t.Errorf("expected 0x1234 44 ; got %#x %v %v", id, seq, ok)
+	}
+	if _, _, _, ok := MatchReply(&icmp.Message{Type: ipv6.ICMPTypeEchoRequest, Body: &icmp.Echo{ID: 0x1234, Seq: 45}}); ok {
+		t.Errorf("matched an echo request")
+	}
+}
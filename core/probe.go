@@ -0,0 +1,129 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Interface Identification Object (RFC 8335 section 2.1)
+const (
+	classInterfaceIdent    = 3
+	typeInterfaceByName    = 1
+	typeInterfaceByIndex   = 2
+	typeInterfaceByAddress = 3
+)
+
+// Address family numbers from the IANA registry
+const (
+	afiIPv4 = 1
+	afiIPv6 = 2
+)
+
+// NewInterfaceIdent builds the object naming the probed interface.
+// A decimal ident is an ifIndex, an IP literal is an address and
+// anything else is treated as an interface name.
+func NewInterfaceIdent(ident string) *icmp.InterfaceIdent {
+	if ip := net.ParseIP(ident); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return &icmp.InterfaceIdent{Class: classInterfaceIdent,
+				Type: typeInterfaceByAddress, AFI: afiIPv4, Addr: ip4}
+		}
+		return &icmp.InterfaceIdent{Class: classInterfaceIdent,
+			Type: typeInterfaceByAddress, AFI: afiIPv6, Addr: ip.To16()}
+	}
+	if index, err := strconv.ParseUint(ident, 10, 32); err == nil {
+		return &icmp.InterfaceIdent{Class: classInterfaceIdent,
+			Type: typeInterfaceByIndex, Index: int(index)}
+	}
+	return &icmp.InterfaceIdent{Class: classInterfaceIdent,
+		Type: typeInterfaceByName, Name: ident}
+}
+
+// NewExtendedEcho constructs an ICMP Extended Echo Request (type 42)
+// probing the interface ident on the target node. An address ident is
+// looked up in the neighbor table of the target when neighbor is set,
+// among its own interfaces otherwise.
+func NewExtendedEcho(ident string, neighbor bool, seq int) icmp.Message {
+	return newExtendedEcho(ipv4.ICMPTypeExtendedEchoRequest, ident, neighbor, seq)
+}
+
+// NewExtendedEcho6 constructs an ICMPv6 Extended Echo Request (type 160).
+func NewExtendedEcho6(ident string, neighbor bool, seq int) icmp.Message {
+	return newExtendedEcho(ipv6.ICMPTypeExtendedEchoRequest, ident, neighbor, seq)
+}
+
+func newExtendedEcho(typ icmp.Type, ident string, neighbor bool, seq int) icmp.Message {
+	ext := NewInterfaceIdent(ident)
+	wm := icmp.Message{
+		Type: typ,
+		Code: 0,
+		Body: &icmp.ExtendedEchoRequest{
+			ID: os.Getpid() & 0xffff, Seq: seq & 0xff,
+			// the L-bit, required when not identifying by address
+			Local:      !neighbor || ext.Type != typeInterfaceByAddress,
+			Extensions: []icmp.Extension{ext},
+		},
+	}
+	return wm
+}
+
+var extendedEchoCodes = []string{
+	"No Error",
+	"Malformed Query",
+	"No Such Interface",
+	"No Such Table Entry",
+	"Multiple Interfaces Satisfy Query",
+}
+
+var extendedEchoStates = []string{
+	"Reserved",
+	"Incomplete",
+	"Reachable",
+	"Stale",
+	"Delay",
+	"Probe",
+	"Failed",
+}
+
+// DescribeExtendedEcho explains an Extended Echo Reply,
+// e.g. "No Error: active ipv4 ipv6".
+func DescribeExtendedEcho(m *icmp.Message) string {
+	reply, ok := m.Body.(*icmp.ExtendedEchoReply)
+	if !ok {
+		return "not an extended echo reply"
+	}
+	code := fmt.Sprintf("code %d", m.Code)
+	if m.Code >= 0 && m.Code < len(extendedEchoCodes) {
+		code = extendedEchoCodes[m.Code]
+	}
+	if m.Code != 0 {
+		return code
+	}
+
+	var bits []string
+	if reply.State > 0 && reply.State < len(extendedEchoStates) {
+		bits = append(bits, "state="+extendedEchoStates[reply.State])
+	}
+	if reply.Active {
+		bits = append(bits, "active")
+	} else {
+		bits = append(bits, "inactive")
+	}
+	if reply.IPv4 {
+		bits = append(bits, "ipv4")
+	}
+	if reply.IPv6 {
+		bits = append(bits, "ipv6")
+	}
+	return code + ": " + strings.Join(bits, " ")
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"testing"
+)
+
+var extendedEchoFixtures = []struct {
+	ident    string
+	neighbor bool
+	kind     int
+	local    bool
+}{
+	{"eth0", false, typeInterfaceByName, true},
+	{"3", false, typeInterfaceByIndex, true},
+	{"192.0.2.1", false, typeInterfaceByAddress, true},
+	{"192.0.2.1", true, typeInterfaceByAddress, false},
+	{"2001:db8::1", true, typeInterfaceByAddress, false},
+	// names & indexes always need the L-bit
+	{"eth0", true, typeInterfaceByName, true},
+}
+
+func TestExtendedEchoRoundTrip(t *testing.T) {
+	for _, tt := range extendedEchoFixtures {
+		wm := NewExtendedEcho(tt.ident, tt.neighbor, 7)
+		if wm.Type != ipv4.ICMPTypeExtendedEchoRequest {
+			t.Errorf("expected %v ; got %v", ipv4.ICMPTypeExtendedEchoRequest, wm.Type)
+		}
+		wb, err := wm.Marshal(nil)
+		if err != nil {
+			t.Fatalf("Marshal(%v): %v", tt.ident, err)
+		}
+		rm, err := icmp.ParseMessage(1, wb)
+		if err != nil {
+			t.Fatalf("ParseMessage(%v): %v", tt.ident, err)
+		}
+		req, ok := rm.Body.(*icmp.ExtendedEchoRequest)
+		if !ok {
+			t.Fatalf("expected *icmp.ExtendedEchoRequest ; got %T", rm.Body)
+		}
+		if req.Seq != 7 || req.Local != tt.local || len(req.Extensions) != 1 {
+			t.Errorf("%v: unexpected request %+v", tt.ident, req)
+			continue
+		}
+		ident, ok := req.Extensions[0].(*icmp.InterfaceIdent)
+		if !ok {
+			t.Errorf("%v: expected *icmp.InterfaceIdent ; got %T", tt.ident, req.Extensions[0])
+			continue
+		}
+		if ident.Type != tt.kind {
+			t.Errorf("%v: expected type %v ; got %v", tt.ident, tt.kind, ident.Type)
+		}
+	}
+}
+
+func TestExtendedEcho6(t *testing.T) {
+	wm := NewExtendedEcho6("2001:db8::1", true, 9)
+	wb, err := wm.Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm, err := icmp.ParseMessage(protocolIPv6ICMP, wb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rm.Type != ipv6.ICMPTypeExtendedEchoRequest {
+		t.Errorf("expected %v ; got %v", ipv6.ICMPTypeExtendedEchoRequest, rm.Type)
+	}
+	req, ok := rm.Body.(*icmp.ExtendedEchoRequest)
+	if !ok || req.Seq != 9 || req.Local {
+		t.Errorf("unexpected request %+v", rm.Body)
+	}
+	if _, err := ParseArgs([]string{"-e", "eth0", "2001:db8::1"}); err != ErrProbeFamily {
+		t.Errorf("expected %v ; got %v", ErrProbeFamily, err)
+	}
+}
+
+func TestParseNeighbor(t *testing.T) {
+	if _, err := ParseArgs([]string{"--neighbor", "-e", "eth0", "localhost"}); err != ErrNeighborIdent {
+		t.Errorf("expected %v ; got %v", ErrNeighborIdent, err)
+	}
+	if arg, err := ParseArgs([]string{"--neighbor", "-e", "192.0.2.1", "127.0.0.1"}); err != nil || !arg.Neighbor {
+		t.Errorf("unexpected %+v %v", arg, err)
+	}
+}
+
+func TestDescribeExtendedEcho(t *testing.T) {
+	rm := &icmp.Message{Type: ipv4.ICMPTypeExtendedEchoReply, Code: 0,
+		Body: &icmp.ExtendedEchoReply{State: 2, Active: true, IPv4: true}}
+	if got := DescribeExtendedEcho(rm); got != "No Error: state=Reachable active ipv4" {
+		t.Errorf("DescribeExtendedEcho: got %v", got)
+	}
+	rm.Code = 2
+	if got := DescribeExtendedEcho(rm); got != "No Such Interface" {
+		t.Errorf("DescribeExtendedEcho: got %v", got)
+	}
+}
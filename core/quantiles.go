@@ -0,0 +1,153 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/thirdparty"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Percentiles are the percentiles goping reports.
+var Percentiles = []float64{50, 90, 95, 99, 99.9}
+
+// quantile turns a percentile into the quantile it is exported as,
+// rounded so that 99.9 is 0.999 rather than 0.9990000000000001.
+func quantile(p float64) float64 {
+	q, _ := strconv.ParseFloat(strconv.FormatFloat(p/100, 'g', 12, 64), 64)
+	return q
+}
+
+const (
+	// quantileAccuracy is the relative error of every reported percentile.
+	quantileAccuracy = 0.005
+	// maxQuantileBuckets bounds the memory of a Quantiles. 2048 buckets
+	// span almost nine orders of magnitude at quantileAccuracy; beyond
+	// that the smallest values are merged, keeping the tail accurate.
+	maxQuantileBuckets = 2048
+	// minQuantileValue & anything smaller land in the zero bucket.
+	minQuantileValue = 1e-9
+)
+
+// Quantiles is a streaming accumulator of percentiles with bounded
+// memory, a DDSketch: values fall in logarithmically sized buckets, so
+// any percentile is within quantileAccuracy of the true value.
+// It is safe for concurrent use.
+type Quantiles struct {
+	lock    sync.Mutex
+	gamma   float64
+	lnGamma float64
+	buckets map[int]uint64
+	zero    uint64
+	count   uint64
+	min     float64
+	max     float64
+}
+
+// NewQuantiles constructs an empty Quantiles.
+func NewQuantiles() *Quantiles {
+	gamma := (1 + quantileAccuracy) / (1 - quantileAccuracy)
+	return &Quantiles{
+		gamma:   gamma,
+		lnGamma: math.Log(gamma),
+		buckets: make(map[int]uint64),
+	}
+}
+
+// Push adds a non negative value, in whatever unit the caller reports.
+func (q *Quantiles) Push(v float64) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.count == 0 || v < q.min {
+		q.min = v
+	}
+	if q.count == 0 || v > q.max {
+		q.max = v
+	}
+	q.count++
+	if v <= minQuantileValue {
+		q.zero++
+		return
+	}
+	q.buckets[int(math.Ceil(math.Log(v)/q.lnGamma))]++
+	if len(q.buckets) > maxQuantileBuckets {
+		q.collapse()
+	}
+}
+
+// collapse merges the two lowest buckets.
+func (q *Quantiles) collapse() {
+	lowest, next := math.MaxInt32, math.MaxInt32
+	for k := range q.buckets {
+		if k < lowest {
+			lowest, next = k, lowest
+		} else if k < next {
+			next = k
+		}
+	}
+	q.buckets[next] += q.buckets[lowest]
+	delete(q.buckets, lowest)
+}
+
+// Count returns how many values were pushed.
+func (q *Quantiles) Count() uint64 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.count
+}
+
+// Percentile returns the p-th percentile, 0 <= p <= 100,
+// or 0 when nothing was pushed.
+func (q *Quantiles) Percentile(p float64) float64 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.count == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(p / 100 * float64(q.count)))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank >= q.count {
+		return q.max
+	}
+	seen := q.zero
+	if seen >= rank {
+		return q.min
+	}
+	keys := make([]int, 0, len(q.buckets))
+	for k := range q.buckets {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, k := range keys {
+		seen += q.buckets[k]
+		if seen >= rank {
+			// the middle of the bucket, in relative terms
+			v := 2 * math.Pow(q.gamma, float64(k)) / (q.gamma + 1)
+			return math.Max(q.min, math.Min(q.max, v))
+		}
+	}
+	return q.max
+}
+
+// FormatPercentiles renders a line like thirdparty.Format's,
+// "rtt p50/p90/p95/p99/p99.9 = 1.2/1.5/1.6/2.1/2.4 ms".
+func FormatPercentiles(q *Quantiles) string {
+	names := make([]string, len(Percentiles))
+	values := make([]string, len(Percentiles))
+	for i, p := range Percentiles {
+		names[i] = fmt.Sprintf("p%v", p)
+		values[i] = fmt.Sprintf("%v", thirdparty.ToFixed(q.Percentile(p), 3))
+	}
+	return fmt.Sprintf("rtt %s = %s ms", strings.Join(names, "/"), strings.Join(values, "/"))
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestQuantilesAccuracy(t *testing.T) {
+	q := NewQuantiles()
+	if q.Percentile(99) != 0 {
+		t.Errorf("expected 0 from an empty Quantiles")
+	}
+	// 1..10000 in shuffled order
+	for i := 0; i < 10000; i++ {
+		q.Push(float64((i*7919)%10000 + 1))
+	}
+	for _, p := range Percentiles {
+		exact := math.Ceil(p / 100 * 10000)
+		got := q.Percentile(p)
+		if math.Abs(got-exact)/exact > quantileAccuracy {
+			t.Errorf("p%v: expected %v within %v ; got %v", p, exact, quantileAccuracy, got)
+		}
+	}
+	if q.Percentile(100) != 10000 || q.Percentile(0) != 1 {
+		t.Errorf("expected max & min ; got %v %v", q.Percentile(100), q.Percentile(0))
+	}
+	expected := "rtt p50/p90/p95/p99/p99.9 = "
+	if !strings.HasPrefix(FormatPercentiles(q), expected) {
+		t.Errorf("expected prefix <%v> ; got <%v>", expected, FormatPercentiles(q))
+	}
+}
+
+func TestQuantilesBoundedMemory(t *testing.T) {
+	q := NewQuantiles()
+	var values []float64
+	for v := 1e-6; v < 1e12; v *= 1.001 {
+		q.Push(v)
+		values = append(values, v)
+	}
+	if len(q.buckets) > maxQuantileBuckets {
+		t.Errorf("expected at most %v buckets ; got %v", maxQuantileBuckets, len(q.buckets))
+	}
+	// the tail survives the merging of the smallest buckets
+	exact := values[int(math.Ceil(0.999*float64(len(values))))-1]
+	if p := q.Percentile(99.9); math.Abs(p-exact)/exact > quantileAccuracy {
+		t.Errorf("expected p99.9 %v ; got %v", exact, p)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/thirdparty"
+	"github.com/erriapo/stats"
+	"io"
+	"sync"
+)
+
+// Responder tallies the replies of one host
+// answering a broadcast or multicast ping.
+type Responder struct {
+	Addr    string
+	Replies uint64
+	Sink    *stats.WelfordSink
+}
+
+// Responders keeps a Responder per replying host,
+// in the order they were first heard from.
+type Responders struct {
+	lock  sync.Mutex
+	order []*Responder
+	m     map[string]*Responder
+}
+
+// NewResponders constructs an empty Responders.
+func NewResponders() *Responders {
+	return &Responders{m: make(map[string]*Responder)}
+}
+
+// OnReply records a reply from addr with its RTT in milliseconds.
+func (r *Responders) OnReply(addr string, rtt float64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	k, ok := r.m[addr]
+	if !ok {
+		k = &Responder{Addr: addr, Sink: stats.NewSink()}
+		r.m[addr] = k
+		r.order = append(r.order, k)
+	}
+	k.Replies += step
+	_ = k.Sink.Push(rtt)
+}
+
+// Len returns the number of distinct responders.
+func (r *Responders) Len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return len(r.order)
+}
+
+// Render writes one line per responder.
+func (r *Responders) Render(w io.Writer) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	fmt.Fprintf(w, "%d responders\n", len(r.order))
+	for _, k := range r.order {
+		fmt.Fprintf(w, "%v: %d replies, %s\n", k.Addr, k.Replies, thirdparty.Format(k.Sink))
+	}
+}
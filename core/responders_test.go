@@ -0,0 +1,25 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestResponders(t *testing.T) {
+	r := NewResponders()
+	r.OnReply("10.0.0.2", 2)
+	r.OnReply("10.0.0.1", 1)
+	r.OnReply("10.0.0.2", 4)
+	var b bytes.Buffer
+	r.Render(&b)
+	expected := "2 responders\n" +
+		"10.0.0.2: 2 replies, rtt min/avg/max/mdev = 2/3/4/1.414 ms\n" +
+		"10.0.0.1: 1 replies, rtt min/avg/max/mdev = 1/1/1/0 ms\n"
+	if b.String() != expected {
+		t.Errorf("expected <%v> ; got <%v>", expected, b.String())
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"time"
+)
+
+// ErrBadInterval means the interval or timeout is not positive.
+var ErrBadInterval = errors.New("interval & timeout must be positive")
+
+// ServeArg holds the command line arguments of goping serve.
+type ServeArg struct {
+	Listen    string
+	Interface string
+	Interval  time.Duration
+	Timeout   time.Duration
+	Help      bool
+	Modules   Modules
+	Targets   []string
+	Windows   []time.Duration
+	Config    string
+	Probes    []*ConfigTarget
+}
+
+// ParseServeArgs parses the arguments following "goping serve".
+func ParseServeArgs(options []string) (*ServeArg, error) {
+	bucket := &ServeArg{Modules: NewModules()}
+
+	f := flag.NewFlagSet("goping serve", flag.ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.BoolVar(&bucket.Help, "h", false, "")
+	f.StringVar(&bucket.Listen, "listen", ":9427", "")
+	f.StringVar(&bucket.Interface, "I", defaultInterface, "")
+	f.DurationVar(&bucket.Interval, "i", time.Second, "")
+	f.DurationVar(&bucket.Timeout, "W", time.Second, "")
+	f.Var(bucket.Modules, "module", "")
+	windows := f.String("windows", "1m,5m,15m", "")
+	f.StringVar(&bucket.Config, "config", "", "")
+
+	if err := f.Parse(options); err != nil {
+		return nil, err
+	}
+	if bucket.Help {
+		return bucket, nil
+	}
+	if bucket.Interval <= 0 || bucket.Timeout <= 0 {
+		return nil, ErrBadInterval
+	}
+	spans, err := ParseWindows(*windows)
+	if err != nil {
+		return nil, err
+	}
+	bucket.Windows = spans
+	// without targets only /probe does any work
+	bucket.Targets = f.Args()
+
+	config := &Config{Defaults: NewModule("defaults")}
+	if bucket.Config != "" {
+		if config, err = LoadConfig(bucket.Config); err != nil {
+			return nil, err
+		}
+	}
+	for _, host := range bucket.Targets {
+		if config.Target(host) == nil {
+			m := *config.Defaults
+			config.Targets = append(config.Targets, &ConfigTarget{Host: host, Settings: &m})
+		}
+	}
+	// flags take precedence over the file
+	f.Visit(func(fl *flag.Flag) {
+		for _, t := range config.Targets {
+			switch fl.Name {
+			case "i":
+				t.Settings.Interval = bucket.Interval
+			case "W":
+				t.Settings.Timeout = bucket.Timeout
+			}
+		}
+	})
+	bucket.Probes = config.Targets
+	return bucket, nil
+}
@@ -0,0 +1,23 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseServeArgs(t *testing.T) {
+	arg, err := ParseServeArgs([]string{"--listen", ":9000", "-i", "5s", "a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arg.Listen != ":9000" || arg.Interval != 5*time.Second || len(arg.Targets) != 2 {
+		t.Errorf("unexpected %+v", arg)
+	}
+	if arg.Modules["icmp"] == nil {
+		t.Errorf("the icmp module should always exist")
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/thirdparty"
+	"github.com/erriapo/stats"
+	"sync"
+)
+
+// CounterSnapshot is a consistent copy of a Counter's totals.
+type CounterSnapshot struct {
+	Sent   uint64
+	Recvd  uint64
+	Errors uint64
+}
+
+// Snapshot copies the totals while the ping keeps running.
+func (c *Counter) Snapshot() CounterSnapshot {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return CounterSnapshot{Sent: c.Sent, Recvd: c.Recvd, Errors: c.Errors}
+}
+
+// Sub returns the probes counted since earlier.
+func (s CounterSnapshot) Sub(earlier CounterSnapshot) CounterSnapshot {
+	return CounterSnapshot{Sent: s.Sent - earlier.Sent, Recvd: s.Recvd - earlier.Recvd, Errors: s.Errors - earlier.Errors}
+}
+
+// Loss returns the packet loss percentage, rounded down like Counter's.
+func (s CounterSnapshot) Loss() uint32 {
+	if s.Sent == 0 || s.Recvd > s.Sent {
+		return 0
+	}
+	return uint32((s.Sent - s.Recvd) * 100 / s.Sent)
+}
+
+// RTTSink guards a stats.WelfordSink, which is not safe for
+// concurrent use, so that snapshots can be taken at any time.
+type RTTSink struct {
+	lock sync.Mutex
+	sink *stats.WelfordSink
+}
+
+// NewRTTSink constructs an empty RTTSink.
+func NewRTTSink() *RTTSink {
+	return &RTTSink{sink: stats.NewSink()}
+}
+
+// Push adds a round trip time.
+func (s *RTTSink) Push(rtt float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_ = s.sink.Push(rtt)
+}
+
+// Snapshot returns a copy of the sink that is safe to read
+// while more round trip times are pushed.
+func (s *RTTSink) Snapshot() *stats.WelfordSink {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	snapshot := *s.sink
+	return &snapshot
+}
+
+// Reset empties the sink & returns what it held.
+func (s *RTTSink) Reset() *stats.WelfordSink {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	old := s.sink
+	s.sink = stats.NewSink()
+	return old
+}
+
+// FormatProgress renders a one line running summary like
+// iputils' SIGQUIT line, "4/5 packets, 20% loss, min/avg/max/mdev = 1/2/3/0.8 ms".
+func FormatProgress(c CounterSnapshot, sink *stats.WelfordSink) string {
+	line := fmt.Sprintf("%d/%d packets, %d%% loss", c.Recvd, c.Sent, c.Loss())
+	if c.Errors > 0 {
+		line += fmt.Sprintf(", +%d errors", c.Errors)
+	}
+	if sink.Count() > 0 {
+		line += fmt.Sprintf(", min/avg/max/mdev = %v/%v/%v/%v ms", thirdparty.ToFixed(sink.Min(), 3),
+			thirdparty.ToFixed(sink.Mean(), 3), thirdparty.ToFixed(sink.Max(), 3), thirdparty.ToFixed(sink.StandardDeviation(), 3))
+	}
+	return line
+}
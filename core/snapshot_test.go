@@ -0,0 +1,63 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"testing"
+)
+
+func TestSnapshotsWhileRunning(t *testing.T) {
+	c, sink := NewCounter(), NewRTTSink()
+	done := make(chan bool)
+	go func() {
+		for i := 1; i <= 1000; i++ {
+			c.OnSent()
+			if i%4 != 0 {
+				c.OnReception()
+				sink.Push(float64(i % 10))
+			}
+		}
+		close(done)
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			if snap := c.Snapshot(); snap.Recvd > snap.Sent {
+				t.Fatalf("inconsistent snapshot %+v", snap)
+			}
+			_ = FormatProgress(c.Snapshot(), sink.Snapshot())
+		}
+	}
+
+	first := c.Snapshot()
+	if first.Sent != 1000 || first.Recvd != 750 || first.Loss() != 25 {
+		t.Errorf("unexpected %+v", first)
+	}
+	c.OnSent()
+	c.NoteAnError()
+	if delta := c.Snapshot().Sub(first); delta.Sent != 1 || delta.Recvd != 0 || delta.Loss() != 100 {
+		t.Errorf("unexpected delta %+v", delta)
+	}
+
+	snap := sink.Snapshot()
+	sink.Push(1000)
+	if snap.Count() != 750 || snap.Max() != 9 {
+		t.Errorf("the snapshot changed: %v %v", snap.Count(), snap.Max())
+	}
+	if old := sink.Reset(); old.Count() != 751 || sink.Snapshot().Count() != 0 {
+		t.Errorf("unexpected reset %v", old.Count())
+	}
+
+	expected := "3/4 packets, 25% loss, +1 errors, min/avg/max/mdev = 1/2/3/1 ms"
+	s := NewRTTSink()
+	for _, rtt := range []float64{1, 2, 3} {
+		s.Push(rtt)
+	}
+	if got := FormatProgress(CounterSnapshot{Sent: 4, Recvd: 3, Errors: 1}, s.Snapshot()); got != expected {
+		t.Errorf("expected <%v> ; got <%v>", expected, got)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Templates are the user supplied output templates.
+// A nil template keeps the built-in line.
+//
+// Reply is executed with an Event for every reply, timeout & ICMP error.
+// Summary & Stats are executed with the final Summary; they replace
+// the packet loss line and the rtt line respectively.
+type Templates struct {
+	Reply   *template.Template
+	Summary *template.Template
+	Stats   *template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	// ms formats nanoseconds as milliseconds, e.g. {{ms .RTT}}
+	"ms": func(ns int64) string {
+		return formatMilli(float64(ns) / float64(time.Millisecond))
+	},
+	// duration formats nanoseconds like 1.234567ms, e.g. {{duration .RTT}}
+	"duration": func(ns int64) time.Duration {
+		return time.Duration(ns)
+	},
+}
+
+// ParseTemplate compiles spec, which is either the template itself
+// or "@path" naming a file that holds it. An empty spec returns nil.
+func ParseTemplate(name, spec string) (*template.Template, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(spec, "@") {
+		b, err := ioutil.ReadFile(spec[1:])
+		if err != nil {
+			return nil, err
+		}
+		spec = string(b)
+	}
+	return template.New(name).Funcs(templateFuncs).Parse(spec)
+}
+
+// NewTemplates compiles the --reply-template, --summary-template
+// & --stats-template options.
+func NewTemplates(arg *Arg) (*Templates, error) {
+	var err error
+	t := new(Templates)
+	if t.Reply, err = ParseTemplate("reply", arg.ReplyTemplate); err != nil {
+		return nil, err
+	}
+	if t.Summary, err = ParseTemplate("summary", arg.SummaryTemplate); err != nil {
+		return nil, err
+	}
+	if t.Stats, err = ParseTemplate("stats", arg.StatsTemplate); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// RenderTemplate executes t & terminates the output with a newline
+// so inline templates don't need one.
+func RenderTemplate(w io.Writer, t *template.Template, data interface{}) error {
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+		b.WriteByte('\n')
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
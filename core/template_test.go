@@ -0,0 +1,43 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	f, err := ioutil.TempFile("", "goping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("{{.Target}} lost {{.Loss}}%\n")
+	f.Close()
+
+	templates, err := NewTemplates(&Arg{
+		ReplyTemplate:   "{{.Seq}} {{.Peer}} {{ms .RTT}}ms {{duration .RTT}}",
+		SummaryTemplate: "@" + f.Name(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if templates.Stats != nil {
+		t.Errorf("expected no stats template ; got %v", templates.Stats)
+	}
+	var b bytes.Buffer
+	RenderTemplate(&b, templates.Reply, Event{Seq: 3, Peer: "127.0.0.1", RTT: 1234567})
+	RenderTemplate(&b, templates.Summary, &Summary{Target: "localhost", Loss: 20})
+	expected := "3 127.0.0.1 1.235ms 1.234567ms\nlocalhost lost 20%\n"
+	if b.String() != expected {
+		t.Errorf("expected <%v> ; got <%v>", expected, b.String())
+	}
+	if _, err := ParseTemplate("reply", "{{.Seq"); err == nil {
+		t.Errorf("expected a parse error")
+	}
+}
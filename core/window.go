@@ -0,0 +1,238 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/thirdparty"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWindows are the sliding windows goping keeps by default.
+var DefaultWindows = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute}
+
+// ewmaWeight is the weight of the latest outcome, 1/8 like iputils' ewma.
+const ewmaWeight = 1.0 / 8
+
+// windowSlots is how many slots divide each window; memory does not grow
+// with the rate of probes & the window slides one slot at a time.
+const windowSlots = 60
+
+// Clock tells the time to the windowed statistics; tests use a fake one.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is the wall clock.
+var SystemClock Clock = systemClock{}
+
+// ParseWindows parses a comma separated list of durations, e.g. "1m,5m,15m".
+func ParseWindows(spec string) ([]time.Duration, error) {
+	var spans []time.Duration
+	for _, field := range strings.Split(spec, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(field))
+		if err != nil || d <= 0 {
+			return nil, ErrBadWindows
+		}
+		spans = append(spans, d)
+	}
+	return spans, nil
+}
+
+// slot aggregates the outcomes of one slice of a window.
+type slot struct {
+	epoch     int64
+	received  uint64
+	lost      uint64
+	sum       float64
+	sumSquare float64
+	min       float64
+	max       float64
+}
+
+type window struct {
+	span  time.Duration
+	width int64
+	slots [windowSlots]slot
+}
+
+func (w *window) slot(now time.Time) *slot {
+	epoch := now.UnixNano() / w.width
+	s := &w.slots[epoch%windowSlots]
+	if s.epoch != epoch {
+		*s = slot{epoch: epoch}
+	}
+	return s
+}
+
+// WindowStats are the statistics of the probes of the last Span.
+// Round trip times are in milliseconds.
+type WindowStats struct {
+	Span     string  `json:"window"`
+	Sent     uint64  `json:"sent"`
+	Received uint64  `json:"received"`
+	Loss     float64 `json:"loss_percent"`
+	Min      float64 `json:"rtt_min_ms"`
+	Avg      float64 `json:"rtt_avg_ms"`
+	Max      float64 `json:"rtt_max_ms"`
+	Mdev     float64 `json:"rtt_mdev_ms"`
+}
+
+// formatSpan renders 5m rather than 5m0s.
+func formatSpan(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+func (w *window) stats(now time.Time) WindowStats {
+	ws := WindowStats{Span: formatSpan(w.span)}
+	epoch := now.UnixNano() / w.width
+	var sum, sumSquare float64
+	for _, s := range w.slots {
+		if s.epoch <= epoch-windowSlots || s.epoch > epoch || s.received+s.lost == 0 {
+			continue
+		}
+		if s.received > 0 {
+			if ws.Received == 0 || s.min < ws.Min {
+				ws.Min = s.min
+			}
+			ws.Max = math.Max(ws.Max, s.max)
+		}
+		ws.Sent += s.received + s.lost
+		ws.Received += s.received
+		sum += s.sum
+		sumSquare += s.sumSquare
+	}
+	if ws.Sent > 0 {
+		ws.Loss = 100 * float64(ws.Sent-ws.Received) / float64(ws.Sent)
+	}
+	if n := float64(ws.Received); n > 0 {
+		ws.Avg = sum / n
+	}
+	if n := float64(ws.Received); n > 1 {
+		ws.Mdev = math.Sqrt(math.Max(0, (sumSquare-sum*sum/n)/(n-1)))
+	}
+	return ws
+}
+
+// EWMA are exponentially weighted moving averages of the round
+// trip time, in milliseconds, & of the loss, in percent.
+type EWMA struct {
+	RTT  float64 `json:"rtt_ms"`
+	Loss float64 `json:"loss_percent"`
+}
+
+// WindowReport is what Windows knows now.
+type WindowReport struct {
+	Windows []WindowStats `json:"windows"`
+	EWMA    EWMA          `json:"ewma"`
+}
+
+// Windows keeps sliding window statistics & moving averages next to
+// the lifetime ones, in bounded memory. It is safe for concurrent use.
+type Windows struct {
+	lock    sync.Mutex
+	clock   Clock
+	windows []*window
+	ewma    EWMA
+	haveRTT bool
+}
+
+// NewWindows keeps a window of every span, sliding on clock.
+func NewWindows(clock Clock, spans []time.Duration) *Windows {
+	w := &Windows{clock: clock}
+	for _, span := range spans {
+		width := int64(span) / windowSlots
+		if width < 1 {
+			width = 1
+		}
+		w.windows = append(w.windows, &window{span: span, width: width})
+	}
+	return w
+}
+
+// Observe adds the outcome of a probe, once per probe; see Repeats.
+func (w *Windows) Observe(e Event) {
+	lost, ok := Lost(e)
+	if !ok {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	now := w.clock.Now()
+	rtt := float64(e.RTT) / float64(time.Millisecond)
+	for _, win := range w.windows {
+		s := win.slot(now)
+		if lost {
+			s.lost++
+			continue
+		}
+		if s.received == 0 || rtt < s.min {
+			s.min = rtt
+		}
+		s.max = math.Max(s.max, rtt)
+		s.received++
+		s.sum += rtt
+		s.sumSquare += rtt * rtt
+	}
+
+	loss := 0.0
+	if lost {
+		loss = 100
+	}
+	w.ewma.Loss += ewmaWeight * (loss - w.ewma.Loss)
+	if !lost {
+		if !w.haveRTT {
+			w.ewma.RTT, w.haveRTT = rtt, true
+		} else {
+			w.ewma.RTT += ewmaWeight * (rtt - w.ewma.RTT)
+		}
+	}
+}
+
+// Report returns the statistics of every window as of now.
+func (w *Windows) Report() *WindowReport {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	now := w.clock.Now()
+	r := &WindowReport{EWMA: w.ewma}
+	for _, win := range w.windows {
+		r.Windows = append(r.Windows, win.stats(now))
+	}
+	return r
+}
+
+// String renders one line per window & one for the moving averages.
+func (r *WindowReport) String() string {
+	var lines []string
+	for _, ws := range r.Windows {
+		line := fmt.Sprintf("last %s: %d/%d packets, %v%% loss", ws.Span, ws.Received, ws.Sent, thirdparty.ToFixed(ws.Loss, 1))
+		if ws.Received > 0 {
+			line += fmt.Sprintf(", rtt min/avg/max/mdev = %v/%v/%v/%v ms", thirdparty.ToFixed(ws.Min, 3),
+				thirdparty.ToFixed(ws.Avg, 3), thirdparty.ToFixed(ws.Max, 3), thirdparty.ToFixed(ws.Mdev, 3))
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, fmt.Sprintf("ewma rtt = %v ms, ewma loss = %v%%",
+		thirdparty.ToFixed(r.EWMA.RTT, 3), thirdparty.ToFixed(r.EWMA.Loss, 1)))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"golang.org/x/net/ipv4"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestSlidingWindows(t *testing.T) {
+	start := time.Unix(1500000000, 0)
+	clock := &fakeClock{now: start}
+	w := NewWindows(clock, []time.Duration{time.Minute, 5 * time.Minute})
+	// 10 minutes, a probe a second: 20ms, then 10ms, then the last minute lost
+	for i := 0; i < 600; i++ {
+		clock.now = start.Add(time.Duration(i) * time.Second)
+		e := Event{Type: EventReply, Seq: i + 1, RTT: int64(20 * time.Millisecond)}
+		switch {
+		case i >= 540:
+			e = Event{Type: EventTimeout, Seq: i + 1}
+		case i >= 300:
+			e.RTT = int64(10 * time.Millisecond)
+		}
+		w.Observe(e)
+	}
+	r := w.Report()
+	if len(r.Windows) != 2 {
+		t.Fatalf("expected 2 windows ; got %+v", r)
+	}
+	if ws := r.Windows[0]; ws.Span != "1m" || ws.Sent != 60 || ws.Received != 0 || ws.Loss != 100 {
+		t.Errorf("unexpected last minute %+v", ws)
+	}
+	if ws := r.Windows[1]; ws.Span != "5m" || ws.Sent != 300 || ws.Received != 240 || ws.Loss != 20 ||
+		ws.Min != 10 || ws.Avg != 10 || ws.Max != 10 || ws.Mdev != 0 {
+		t.Errorf("unexpected last 5 minutes %+v", ws)
+	}
+	if loss := 100 * (1 - math.Pow(7.0/8, 60)); math.Abs(r.EWMA.Loss-loss) > 1e-9 || math.Abs(r.EWMA.RTT-10) > 1e-9 {
+		t.Errorf("expected ewma 10 ms & %v%% ; got %+v", loss, r.EWMA)
+	}
+	expected := "last 1m: 0/60 packets, 100% loss\n" +
+		"last 5m: 240/300 packets, 20% loss, rtt min/avg/max/mdev = 10/10/10/0 ms\n" +
+		"ewma rtt = 10 ms, ewma loss = 100%"
+	if r.String() != expected {
+		t.Errorf("expected <%v> ; got <%v>", expected, r.String())
+	}
+
+	// the slots of an idle window expire rather than pile up
+	clock.now = clock.now.Add(5 * time.Minute)
+	for _, ws := range w.Report().Windows {
+		if ws.Sent != 0 {
+			t.Errorf("expected an empty window ; got %+v", ws)
+		}
+	}
+
+	m := NewMetrics()
+	m.SetWindows(clock, []time.Duration{time.Minute})
+	m.Observe("a.example", Result{Type: ipv4.ICMPTypeEchoReply, RTT: 4 * time.Millisecond})
+	m.Observe("a.example", Result{Err: ErrTimeout})
+	var b bytes.Buffer
+	m.Render(&b)
+	for _, line := range []string{
+		`goping_window_loss_ratio{target="a.example",window="1m"} 0.5`,
+		`goping_window_rtt_avg_seconds{target="a.example",window="1m"} 0.004`,
+		`goping_rtt_ewma_seconds{target="a.example"} 0.004`,
+		`goping_loss_ewma_ratio{target="a.example"} 0.125`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("missing <%v> in\n%v", line, b.String())
+		}
+	}
+
+	if arg, err := ParseArgs([]string{"--windows", "30s,2h", "localhost"}); err != nil ||
+		len(arg.Windows) != 2 || arg.Windows[1] != 2*time.Hour {
+		t.Errorf("expected 30s & 2h ; got %v %v", arg, err)
+	}
+	if _, err := ParseArgs([]string{"--windows", "1m,-5m", "localhost"}); err != ErrBadWindows {
+		t.Errorf("expected %v ; got %v", ErrBadWindows, err)
+	}
+}
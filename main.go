@@ -2,6 +2,7 @@
 // Use of this source code is governed by the MIT
 // license that can be found in the LICENSE file.
 
+//go:build darwin || freebsd || linux || netbsd || openbsd
 // +build darwin freebsd linux netbsd openbsd
 
 package main
@@ -9,14 +10,13 @@ package main
 import (
 	"fmt"
 	"github.com/erriapo/goping/core"
-	"github.com/erriapo/goping/thirdparty"
-	"github.com/erriapo/stats"
 	"golang.org/x/net/icmp"
 	"golang.org/x/net/ipv4"
 	"log"
 	"net"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -30,7 +30,10 @@ const payloadAndHeader = payloadLen + ipheader + icmpheader
 // milliseconds to pause between sending each ICMP request
 const pause = 1
 
-var accountant = stats.NewSink()
+var accountant = core.NewRTTSink()
+var percentiles = core.NewQuantiles()
+var jitter = core.NewJitter()
+var bursts = core.NewBursts()
 var cache = core.NewCache()
 var counter = core.NewCounter()
 var pingHeading bool
@@ -54,44 +57,141 @@ func nanoToMilli(d time.Duration) float64 {
 	return float64(d.Nanoseconds()) / float64(1000000)
 }
 
+// observe records a round trip time in milliseconds.
+func observe(rtt time.Duration) {
+	accountant.Push(nanoToMilli(rtt))
+	interval.Push(nanoToMilli(rtt))
+	percentiles.Push(nanoToMilli(rtt))
+	jitter.Push(nanoToMilli(rtt))
+}
+
+// printExtensions shows the MPLS & interface objects a router
+// attached to an ICMP error.
+func printExtensions(rm *icmp.Message) {
+	for _, ext := range core.Extensions(rm) {
+		fmt.Fprintf(os.Stderr, "\t  %s\n", core.DescribeExtension(ext))
+	}
+}
+
+// describeError names the ICMP errors MatchReply pairs with a probe.
+func describeError(rm *icmp.Message) string {
+	switch rm.Type {
+	case ipv4.ICMPTypeDestinationUnreachable:
+		return "Destination unreachable"
+	case ipv4.ICMPTypeTimeExceeded:
+		return "Time to live exceeded"
+	case ipv4.ICMPTypeParameterProblem:
+		return "Parameter problem"
+	}
+	return fmt.Sprintf("ICMP type %v", rm.Type)
+}
+
+// setDeadline applies to the socket in use, c or raw.
+func setDeadline(c *icmp.PacketConn, raw *ipv4.RawConn, t time.Time) error {
+	if raw != nil {
+		return raw.SetDeadline(t)
+	}
+	return c.SetDeadline(t)
+}
+
+// send writes the ICMP packet, through the raw socket
+// when IPv4 options were requested.
+func send(c *icmp.PacketConn, raw *ipv4.RawConn, wb []byte, host *net.IPAddr, ipopts []byte, ttl int) error {
+	if raw != nil {
+		h := core.NewIPv4Header(host.IP, ipopts, len(wb))
+		if ttl > 0 {
+			h.TTL = ttl
+		}
+		return raw.WriteTo(h, wb, nil)
+	}
+	_, err := c.WriteTo(wb, host)
+	return err
+}
+
+// receive reads the next ICMP packet & its TTL. The IPv4 header
+// is only returned when reading from the raw socket.
+func receive(c *icmp.PacketConn, raw *ipv4.RawConn, rb []byte) ([]byte, net.Addr, int, *ipv4.Header, error) {
+	if raw != nil {
+		h, p, _, err := raw.ReadFrom(rb)
+		if err != nil {
+			return nil, nil, 0, nil, err
+		}
+		return p, &net.IPAddr{IP: h.Src}, h.TTL, h, nil
+	}
+	n, cm, peer, err := c.IPv4PacketConn().ReadFrom(rb)
+	if err != nil {
+		return nil, peer, 0, nil, err
+	}
+	ttl := 0
+	if cm != nil {
+		ttl = cm.TTL
+	}
+	return rb[:n], peer, ttl, nil, nil
+}
+
 func main() {
-	help, verbose, count, host, cname, iface, err := core.ParseOption(os.Args[1:])
-	if help {
-		fmt.Fprintf(os.Stderr, "%s", core.Usage)
-		os.Exit(2)
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		serve(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "analyze" {
+		analyze(os.Args[2:])
+		return
 	}
 
+	arg, err := core.ParseArgs(os.Args[1:])
+	if err != nil && checking(os.Args[1:]) {
+		os.Exit(core.RenderUnknown(os.Stdout, err))
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Aborted: %v\n", err)
 		fmt.Fprintf(os.Stderr, "%s", core.Usage)
-		os.Exit(2)
+		os.Exit(exitFailure)
+	}
+
+	if arg.Help {
+		fmt.Fprintf(os.Stderr, "%s", core.Usage)
+		os.Exit(exitFailure)
+	}
+	verbose, count, host, cname, iface := arg.Extra, arg.Count, arg.Target, arg.CNAME, arg.Interface
+	if err := openOutputs(arg); err != nil {
+		fatal(err)
+	}
+	if arg.PushEvery > 0 {
+		go pushEvery(arg.PushEvery, choose(cname, host))
 	}
 
 	// It is safe to ignore the error as we will fallback
 	// to the supplied Host
 	suppliedFQDN, suppliedErr := cache.Reverse(host)
 	if verbose {
-		fmt.Printf("Reversed lookup of supplied host = %v\n", suppliedFQDN)
-		fmt.Printf("CNAME = %v\n", cname)
+		fmt.Fprintf(out, "Reversed lookup of supplied host = %v\n", suppliedFQDN)
+		fmt.Fprintf(out, "CNAME = %v\n", cname)
 	}
 
-	// trap CTRL+C
-	exitchan := make(chan os.Signal, 1)
-	signal.Notify(exitchan, os.Interrupt) // SIGINT
+	if arg.SummaryEvery > 0 {
+		go summaryEvery(arg.SummaryEvery)
+	}
+
+	// CTRL+C & SIGTERM print the summary & stop; CTRL+\ prints a running summary
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
 	go func() {
-		<-exitchan
-		counter.Render(os.Stdout, heading(choose(cname, host)))
-		if counter.NeedStatistics() {
-			fmt.Printf("%s\n", thirdparty.Format(accountant))
+		for sig := range signals {
+			if sig == syscall.SIGQUIT {
+				fmt.Fprintf(os.Stderr, "%s\n", core.FormatProgress(counter.Snapshot(), accountant.Snapshot()))
+				continue
+			}
+			summarize(choose(cname, host))
+			exit(choose(cname, host))
 		}
-		os.Exit(1)
 	}()
 
 	var ifacetarget = net.IPv4zero
 	allinterfaces, ifaceerr := core.ScanInterfaces()
 	if ifaceerr == nil {
 		if verbose {
-			fmt.Printf("Scanning interfaces: %v\n", allinterfaces)
+			fmt.Fprintf(out, "Scanning interfaces: %v\n", allinterfaces)
 		}
 		if iface != "0.0.0.0" {
 			ip, ok := allinterfaces[iface]
@@ -101,18 +201,61 @@ func main() {
 		}
 	}
 
-	c, err := icmp.ListenPacket("ip4:icmp", ifacetarget.String())
-	if err != nil {
-		log.Fatal(err)
+	if arg.ARP {
+		arping(arg)
+	}
+	if arg.ND {
+		ndping(arg)
+	}
+	if arg.Broadcast {
+		broadcast(arg, ifacetarget)
+	}
+
+	// ParseArgs has already validated -R & -T
+	ipopts, _ := core.NewIPOptions(arg.Record, arg.Timestamp)
+	// one socket: the raw one when IPv4 options were requested
+	var c *icmp.PacketConn
+	var raw *ipv4.RawConn
+	if len(ipopts) > 0 {
+		pc, err := net.ListenPacket("ip4:icmp", ifacetarget.String())
+		if err != nil {
+			fatal(err)
+		}
+		if raw, err = ipv4.NewRawConn(pc); err != nil {
+			fatal(err)
+		}
+		defer raw.Close()
+	} else {
+		c, err = icmp.ListenPacket("ip4:icmp", ifacetarget.String())
+		if err != nil {
+			fatal(err)
+		}
+		defer c.Close()
+		if arg.TTL > 0 {
+			if err := c.IPv4PacketConn().SetTTL(arg.TTL); err != nil {
+				fatal(err)
+			}
+		}
+		// not every platform reports the TTL; it is then left out
+		_ = c.IPv4PacketConn().SetControlMessage(ipv4.FlagTTL, true)
+	}
+
+	// the capture shows the address the kernel sends from, not 0.0.0.0
+	source := ifacetarget
+	if source.Equal(net.IPv4zero) {
+		if ip := core.SourceAddr(host.IP); ip != nil {
+			source = ip
+		}
 	}
-	defer c.Close()
 
 	var wm icmp.Message
 	var wb []byte
 
 	rb := make([]byte, 1500)
 
-	var t1 time.Time
+	// the size, interval & timeout of --config, if any
+	echo := string(core.NewPayload(arg.Size))
+	matcher := core.NewMatcher(arg.Timeout)
 	var peer2 net.Addr
 	var peer2FQDN string
 	var peer2err error
@@ -122,100 +265,142 @@ func main() {
 
 nn:
 	for i := 1; i <= int(count); i++ {
-		wm = core.NewEcho(payload, i)
+		if arg.Probe != "" {
+			wm = core.NewExtendedEcho(arg.Probe, arg.Neighbor, i)
+		} else {
+			wm = core.NewEcho(echo, i)
+		}
 		wb, err = wm.Marshal(nil)
 		if err != nil {
-			log.Fatal(err)
+			fatal(err)
 		}
-		//t1, _ := time.Parse(time.RFC3339, "2017-06-28T19:55:50+00:00")
-		t1 = time.Now().Add(time.Second * 6)
-		if err := c.SetDeadline(t1); err != nil {
+		time.Sleep(arg.Interval)
+		start := time.Now()
+		if err := setDeadline(c, raw, start.Add(matcher.Window)); err != nil {
 			fmt.Fprintf(os.Stderr, "Unable to set read & write Deadline.")
-			log.Fatal("Unable to continue. Halted")
+			fatal("Unable to continue. Halted")
 		}
-		time.Sleep(pause * time.Second)
-		start := time.Now()
-		if _, err := c.WriteTo(wb, host); err != nil {
+		if err := send(c, raw, wb, host, ipopts, arg.TTL); err != nil {
 			fmt.Fprintf(os.Stderr, "%d connect: Network is unreachable\n", i)
+			unsent++
 			continue nn
 		}
 		counter.OnSent()
+		matcher.Sent(host.IP, &wm, start)
+		events.Emit(core.Event{Type: core.EventSent, Time: start, Target: host.String(), Seq: i})
+		record(start, source, host.IP, arg.TTL, wb, true, fmt.Sprintf("probe icmp_seq=%d", i))
 
-		// TODO we need to loop until we receive an echo reply
-		//hh := c.IPv4PacketConn()
-		//k, cm, _, errgg := hh.ReadFrom(rb2)
-		//fmt.Printf("!!! %v --- %v %v\n", cm, errgg, k)
-
-		n, peer, err := c.ReadFrom(rb)
-		if verbose {
-			fmt.Printf("peer %v vs host %v\n", peer, host)
-		}
-		if peer != nil {
-			peer2 = peer
-		}
-
-		if !pingHeading {
-			fmt.Printf("PING %v (%v) %v(%v) bytes of data.\n", choose(cname, peer), host, payloadLen, payloadAndHeader)
-			pingHeading = true
-		}
-
-		if err != nil {
-			fmt.Printf("%v bytes from %v (%v): icmp_seq=%v No response\n", 0, choose(suppliedFQDN, host), host, i)
+		// read until the answer to this probe or the deadline; the
+		// matcher of goping analyze drops whatever else is received
+		for {
+			reply, peer, ttl, replyHeader, err := receive(c, raw, rb)
+			received := time.Now()
+			n := len(reply)
 			if verbose {
-				fmt.Fprintf(os.Stderr, "\t%+v\n", err)
+				fmt.Fprintf(out, "peer %v vs host %v\n", peer, host)
 			}
-			continue nn
-		}
-		elapsed := time.Since(start)
 
-		peer2FQDN, peer2err = cache.Reverse(peer2)
-		h := core.ChoosePeer(suppliedFQDN, host, suppliedErr, peer2FQDN, peer2, peer2err)
-		if verbose {
-			fmt.Printf("ChoosePeer() returned %v\n", h)
-		}
-		fmt.Printf("%v bytes from %v (%v): icmp_seq=%v time=%v\n", n, h.FQDN, h.IP, i, elapsed)
-		if verbose {
-			fmt.Printf("RTT %d ns\n", elapsed.Nanoseconds())
-		}
+			if !pingHeading {
+				fmt.Fprintf(out, "PING %v (%v) %v(%v) bytes of data.\n", choose(cname, peer), host, len(echo), len(echo)+ipheader+icmpheader)
+				pingHeading = true
+			}
 
-		rm, err := icmp.ParseMessage(1, rb[:n])
-		//hder, _ := icmp.ParseIPv4Header(rb[:n])
-		//fmt.Printf("REPLY %v -> %v\n", rm, hder)
-		if err != nil {
-			log.Fatal(err)
-		}
-		switch rm.Type {
-		case ipv4.ICMPTypeEcho:
-			if verbose {
-				log.Printf("\t%+v; echo", rm)
+			if err != nil {
+				if templates.Reply == nil {
+					fmt.Fprintf(out, "%v bytes from %v (%v): icmp_seq=%v No response\n", 0, choose(suppliedFQDN, host), host, i)
+				}
+				report(core.Event{Type: core.EventTimeout, Target: host.String(), Seq: i, Error: err.Error()})
+				if verbose {
+					fmt.Fprintf(os.Stderr, "\t%+v\n", err)
+				}
+				continue nn
 			}
-		case ipv4.ICMPTypeEchoReply:
-			counter.OnReception()
-			accountant.Push(nanoToMilli(elapsed))
-			if verbose {
-				log.Printf("\t%+v; echo reply", rm)
+			annotate := func(note string) {
+				if addr, ok := peer.(*net.IPAddr); ok {
+					record(received, addr.IP, source, ttl, reply, false, note)
+				}
 			}
-		case ipv4.ICMPTypeDestinationUnreachable:
-			counter.NoteAnError()
-			fmt.Fprintf(os.Stderr, "\tDestination unreachable.\n")
-			if verbose {
-				log.Printf("%+v;", rm)
+			rm, err := icmp.ParseMessage(1, reply)
+			if err != nil {
+				annotate("ignored: malformed ICMP")
+				continue
+			}
+			var src net.IP
+			if addr, ok := peer.(*net.IPAddr); ok {
+				src = addr.IP
 			}
-		case ipv4.ICMPTypeTimeExceeded:
-			counter.NoteAnError()
-			fmt.Fprintf(os.Stderr, "\tTime to live exceeded.\n")
+			m, verdict := matcher.Match(rm, src, received)
+			if verdict != core.Matched {
+				note := "ignored: " + verdict.String()
+				if verdict == core.Ignored {
+					note = fmt.Sprintf("ignored: %v", rm.Type)
+				}
+				annotate(note)
+				if verbose {
+					log.Printf("\t%+v; %s", rm, note)
+				}
+				continue
+			}
+			// map the wire sequence number back to the probe, as an
+			// echo reply may still follow an error for an earlier one
+			mask := 0xffff
+			if arg.Probe != "" {
+				mask = 0xff
+			}
+			seq := i - (i-m.Seq)&mask
+			elapsed := m.RTT
+			annotate(fmt.Sprintf("matched icmp_seq=%d", seq))
+			peer2 = peer
+
+			peer2FQDN, peer2err = cache.Reverse(peer2)
+			h := core.ChoosePeer(suppliedFQDN, host, suppliedErr, peer2FQDN, peer2, peer2err)
 			if verbose {
-				log.Printf("%+v;", rm)
+				fmt.Fprintf(out, "ChoosePeer() returned %v\n", h)
+			}
+			if templates.Reply == nil {
+				if ttl > 0 {
+					fmt.Fprintf(out, "%v bytes from %v (%v): icmp_seq=%v ttl=%v time=%v\n", n, h.FQDN, h.IP, seq, ttl, elapsed)
+				} else {
+					fmt.Fprintf(out, "%v bytes from %v (%v): icmp_seq=%v time=%v\n", n, h.FQDN, h.IP, seq, elapsed)
+				}
+			}
+			if replyHeader != nil && len(replyHeader.Options) > 0 {
+				fmt.Fprintln(out, core.ParseIPOptions(replyHeader.Options))
 			}
-		default:
 			if verbose {
-				log.Printf("\tunexpected %+v;", rm)
+				fmt.Fprintf(out, "RTT %d ns\n", elapsed.Nanoseconds())
+			}
+			event := core.Event{Type: core.EventReply, Target: host.String(), Seq: seq, TTL: ttl,
+				RTT: elapsed.Nanoseconds(), Peer: h.IP, FQDN: h.FQDN}
+
+			switch rm.Type {
+			case ipv4.ICMPTypeEchoReply, ipv4.ICMPTypeExtendedEchoReply:
+				counter.OnReception()
+				observe(elapsed)
+				if rm.Type == ipv4.ICMPTypeExtendedEchoReply {
+					fmt.Fprintf(out, "\tinterface %v: %v\n", arg.Probe, core.DescribeExtendedEcho(rm))
+					event.Status = core.DescribeExtendedEcho(rm)
+				}
+				report(event)
+				if verbose {
+					log.Printf("\t%+v; echo reply", rm)
+				}
+			default:
+				counter.NoteAnError()
+				fmt.Fprintf(os.Stderr, "\t%s.\n", describeError(rm))
+				printExtensions(rm)
+				event.Type, event.Error = core.EventError, describeError(rm)
+				event.ICMPType, event.ICMPCode = int(rm.Type.(ipv4.ICMPType)), rm.Code
+				report(event)
+				if verbose {
+					log.Printf("%+v;", rm)
+				}
+			}
+			if seq == i {
+				continue nn
 			}
 		}
 	}
-	counter.Render(os.Stdout, heading(choose(cname, peer2)))
-	if counter.NeedStatistics() {
-		fmt.Printf("%s\n", thirdparty.Format(accountant))
-	}
-	os.Exit(0)
+	summarize(choose(cname, peer2))
+	exit(choose(cname, peer2))
 }
@@ -0,0 +1,69 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd || linux || netbsd || openbsd
+// +build darwin freebsd linux netbsd openbsd
+
+package main
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/core"
+	"net"
+	"os"
+	"time"
+)
+
+// ndping checks an on-link IPv6 neighbour with Neighbor Solicitations.
+func ndping(arg *core.Arg) {
+	ifi, err := net.InterfaceByName(arg.Interface)
+	if err != nil {
+		fatal(err)
+	}
+	p, err := core.NewNDPinger(ifi, arg.Target.IP)
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Fprintf(out, "NDPING %v (%v) on %v\n", arg.Target.IP, core.SolicitedNodeAddr(arg.Target.IP), ifi.Name)
+	for i := 1; i <= int(arg.Count); i++ {
+		counter.OnSent()
+		events.Emit(core.Event{Type: core.EventSent, Target: arg.Target.IP.String(), Seq: i})
+		responses, err := p.Ping(pause * time.Second)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%d send: %v\n", i, err)
+			continue
+		}
+		if len(responses) == 0 {
+			if templates.Reply == nil {
+				fmt.Fprintf(out, "Timeout from %v: nd_seq=%v\n", arg.Target.IP, i)
+			}
+			report(core.Event{Type: core.EventTimeout, Target: arg.Target.IP.String(), Seq: i})
+			continue
+		}
+		counter.OnReception()
+		observe(responses[0].RTT)
+		for _, r := range responses {
+			flags := ""
+			if r.Advert.Router {
+				flags += " router"
+			}
+			if r.Advert.Solicited {
+				flags += " solicited"
+			}
+			if r.Advert.Override {
+				flags += " override"
+			}
+			if templates.Reply == nil {
+				fmt.Fprintf(out, "Advertisement from %v [%v]: nd_seq=%v time=%v%s\n", r.From, r.Advert.MAC, i, r.RTT, flags)
+			}
+			report(core.Event{Type: core.EventReply, Target: arg.Target.IP.String(), Seq: i,
+				RTT: r.RTT.Nanoseconds(), Peer: r.From.String(), MAC: r.Advert.MAC.String()})
+		}
+	}
+	p.Close()
+
+	summarize(arg.Target.IP.String())
+	exit(arg.Target.IP.String())
+}
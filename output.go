@@ -0,0 +1,339 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd || linux || netbsd || openbsd
+// +build darwin freebsd linux netbsd openbsd
+
+package main
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/core"
+	"github.com/erriapo/goping/thirdparty"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// out receives the human readable output. It is discarded
+// when a machine readable --format owns stdout.
+var out io.Writer = os.Stdout
+
+// events is empty unless a machine readable output was requested.
+var events core.Outputs
+
+// capture records the packets when --pcap is given.
+var capture *core.PcapWriter
+
+// outages logs the target going down & up again.
+var outages = core.NewOutageLog("", 3, 1, ioutil.Discard)
+
+// windows keeps the sliding window statistics of --windows.
+var windows = core.NewWindows(core.SystemClock, core.DefaultWindows)
+
+// changes spots shifts & spikes of the round trip time.
+var changes = core.NewChangeDetector()
+
+// checks holds the thresholds of --check.
+var checks *core.Check
+
+// templates holds --reply-template, --summary-template & --stats-template.
+var templates = new(core.Templates)
+
+// openOutputs sets up --format, --csv, --csv-summary, --pcap, the collectors & the templates.
+func openOutputs(arg *core.Arg) error {
+	t, err := core.NewTemplates(arg)
+	if err != nil {
+		return err
+	}
+	templates = t
+
+	maxLoss = arg.MaxLoss
+	if arg.Check {
+		// ParseArgs has already validated -w & -c
+		checks, _ = core.NewCheck(arg.Warning, arg.Critical)
+		// the plugin output is a single line
+		out = ioutil.Discard
+	}
+
+	o, err := core.NewOutput(os.Stdout, arg.Format)
+	if err != nil {
+		return err
+	}
+	if o != nil {
+		events = append(events, o)
+		out = ioutil.Discard
+	}
+	csv := o
+	if arg.CSV != "" {
+		f, err := os.Create(arg.CSV)
+		if err != nil {
+			return err
+		}
+		if csv, err = core.NewOutput(f, core.FormatCSV); err != nil {
+			return err
+		}
+		events = append(events, csv)
+	}
+	if arg.CSVSummary != "" {
+		f, err := os.Create(arg.CSVSummary)
+		if err != nil {
+			return err
+		}
+		csv.SetSummary(f)
+	}
+
+	if arg.Pcap != "" {
+		f, err := os.Create(arg.Pcap)
+		if err != nil {
+			return err
+		}
+		if capture, err = core.NewPcapWriter(f); err != nil {
+			return err
+		}
+	}
+
+	outages = core.NewOutageLog(arg.Host, arg.DownAfter, arg.UpAfter, out)
+	windows = core.NewWindows(core.SystemClock, arg.Windows)
+
+	tags := collectorTags(arg)
+	if o != nil {
+		o.SetTags(tags)
+	}
+	for _, collector := range []struct{ dest, format string }{
+		{arg.Influx, core.FormatInflux},
+		{statsdDestination(arg.StatsD), core.FormatStatsD},
+	} {
+		if collector.dest == "" {
+			continue
+		}
+		w, err := core.OpenDestination(collector.dest)
+		if err != nil {
+			return err
+		}
+		c, _ := core.NewOutput(w, collector.format)
+		c.SetTags(tags)
+		events = append(events, c)
+	}
+	if arg.OTLP != "" {
+		c := core.NewOTLPOutput(core.OpenOTLP(arg.OTLP))
+		c.SetTags(tags)
+		events = append(events, c)
+	}
+	return nil
+}
+
+// record adds an ICMP message to the --pcap capture.
+func record(t time.Time, src, dst net.IP, ttl int, message []byte, outbound bool, note string) {
+	if capture == nil {
+		return
+	}
+	if err := capture.WriteICMP(t, src, dst, ttl, message, outbound, note); err != nil {
+		log.Printf("pcap: %v", err)
+	}
+}
+
+// statsdDestination accepts host:port as well as udp://host:port.
+func statsdDestination(addr string) string {
+	if addr == "" || strings.Contains(addr, "://") {
+		return addr
+	}
+	return "udp://" + addr
+}
+
+// collectorTags labels the measurements sent to InfluxDB, StatsD & OTLP.
+func collectorTags(arg *core.Arg) core.Tags {
+	t := core.Tags{Target: arg.Host, Protocol: "icmp"}
+	if arg.Target != nil {
+		t.IP = arg.Target.IP.String()
+	}
+	if arg.Interface != "0.0.0.0" {
+		t.Interface = arg.Interface
+	}
+	switch {
+	case arg.ARP:
+		t.Protocol = "arp"
+	case arg.ND:
+		t.Protocol = "nd"
+	}
+	return t
+}
+
+// newSummary gathers the statistics of the run so far.
+func newSummary(target string) *core.Summary {
+	summary := core.NewSummary(target, counter, accountant.Snapshot(), percentiles)
+	summary.SetJitter(jitter)
+	summary.Bursts = bursts.Report()
+	summary.SetOutages(outages, time.Now())
+	summary.Windows = windows.Report()
+	return summary
+}
+
+// pushEvery sends interim statistics to the collectors until the process exits.
+func pushEvery(d time.Duration, target string) {
+	for range time.Tick(d) {
+		events.Report(newSummary(target))
+	}
+}
+
+// interval holds the round trip times since the last --summary-every line.
+var interval = core.NewRTTSink()
+
+// summaryEvery prints the statistics of every period d until the process exits.
+func summaryEvery(d time.Duration) {
+	last := counter.Snapshot()
+	for range time.Tick(d) {
+		now := counter.Snapshot()
+		fmt.Fprintf(out, "--- last %v: %s\n", d, core.FormatProgress(now.Sub(last), interval.Reset()))
+		fmt.Fprintf(out, "%s\n", windows.Report())
+		last = now
+	}
+}
+
+// repeats keeps the duplicate replies out of the loss & latency statistics.
+var repeats = new(core.Repeats)
+
+// report hands e to the machine readable outputs and,
+// when given, prints it with --reply-template.
+func report(e core.Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Version = core.SchemaVersion
+	repeated := repeats.Repeated(e)
+	if !repeated {
+		bursts.Observe(e)
+		outages.Observe(e)
+		windows.Observe(e)
+	}
+	events.Emit(e)
+	if templates.Reply != nil {
+		if err := core.RenderTemplate(out, templates.Reply, e); err != nil {
+			log.Printf("reply template: %v", err)
+		}
+	}
+	if repeated {
+		return
+	}
+	for _, change := range changes.Observe(e) {
+		fmt.Fprintf(out, "%s\n", core.FormatChange(change))
+		events.Emit(change)
+	}
+}
+
+// Exit statuses of a run without --check, those of iputils' ping.
+const (
+	exitReplies = 0
+	exitNoReply = 1
+	exitFailure = 2
+)
+
+// maxLoss is --max-loss.
+var maxLoss = 100.0
+
+// unsent counts the probes that could not be sent.
+var unsent uint64
+
+// exitStatus returns exitReplies when a reply arrived & no more than
+// maxLoss percent of the probes were lost, exitFailure when no probe
+// could be sent & exitNoReply otherwise.
+func exitStatus(s *core.Summary) int {
+	switch {
+	case s.Sent == 0 && unsent > 0:
+		return exitFailure
+	case s.Received == 0 || s.ExactLoss() > maxLoss:
+		return exitNoReply
+	}
+	return exitReplies
+}
+
+// exit ends the run with a status telling how it went, that of
+// the checks when --check is given.
+func exit(target string) {
+	if checks != nil {
+		os.Exit(checks.Render(os.Stdout, newSummary(target)))
+	}
+	os.Exit(exitStatus(newSummary(target)))
+}
+
+// fatal logs v & exits with exitFailure, or UNKNOWN when --check is given.
+func fatal(v ...interface{}) {
+	if checks != nil {
+		os.Exit(core.RenderUnknown(os.Stdout, fmt.Sprint(v...)))
+	}
+	log.Print(v...)
+	os.Exit(exitFailure)
+}
+
+// checking tells whether the arguments ask for --check,
+// even when they do not parse.
+func checking(args []string) bool {
+	for _, a := range args {
+		if a == "--check" || a == "-check" {
+			return true
+		}
+	}
+	return false
+}
+
+// trailer prints what a mode adds to the statistics, e.g. the
+// responders of a broadcast ping, be the run over or interrupted.
+// The signal goroutine reads it, so it is set with setTrailer.
+var trailer func()
+
+var trailerLock sync.Mutex
+
+func setTrailer(f func()) {
+	trailerLock.Lock()
+	defer trailerLock.Unlock()
+	trailer = f
+}
+
+// summarizing makes sure a signal arriving as the run
+// ends doesn't print the statistics a second time.
+var summarizing sync.Once
+
+// summarize prints the statistics of the run.
+func summarize(target string) {
+	summarizing.Do(func() { printSummary(target) })
+}
+
+func printSummary(target string) {
+	summary := newSummary(target)
+	if templates.Summary != nil {
+		if err := core.RenderTemplate(out, templates.Summary, summary); err != nil {
+			log.Printf("summary template: %v", err)
+		}
+	} else {
+		counter.Render(out, heading(target))
+	}
+	if counter.NeedStatistics() {
+		if templates.Stats != nil {
+			if err := core.RenderTemplate(out, templates.Stats, summary); err != nil {
+				log.Printf("stats template: %v", err)
+			}
+		} else {
+			fmt.Fprintf(out, "%s\n", thirdparty.Format(accountant.Snapshot()))
+			fmt.Fprintf(out, "%s\n", core.FormatPercentiles(percentiles))
+			fmt.Fprintf(out, "%s\n", jitter.Format())
+			fmt.Fprintf(out, "%s\n", core.FormatEModel(summary))
+		}
+	}
+	if summary.Bursts != nil && templates.Stats == nil {
+		fmt.Fprintf(out, "%s\n", summary.Bursts)
+	}
+	core.RenderOutages(out, summary, time.Now())
+	trailerLock.Lock()
+	f := trailer
+	trailerLock.Unlock()
+	if f != nil {
+		f()
+	}
+	events.Finish(summary)
+}
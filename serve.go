@@ -0,0 +1,114 @@
+// Copyright 2026 Gavin Chun Jin. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd || linux || netbsd || openbsd
+// +build darwin freebsd linux netbsd openbsd
+
+package main
+
+import (
+	"fmt"
+	"github.com/erriapo/goping/core"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// exposition is the Content-Type of the Prometheus text format.
+const exposition = "text/plain; version=0.0.4; charset=utf-8"
+
+// serve keeps probing every target and exposes
+// the results on /metrics for Prometheus.
+func serve(options []string) {
+	arg, err := core.ParseServeArgs(options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Aborted: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%s", core.ServeUsage)
+		os.Exit(2)
+	}
+	if arg.Help {
+		fmt.Fprintf(os.Stderr, "%s", core.ServeUsage)
+		os.Exit(2)
+	}
+
+	targets := make(map[*core.ConfigTarget]*net.IPAddr)
+	for _, t := range arg.Probes {
+		ip := t.Settings.Resolve(t.Host)
+		if ip == nil {
+			fmt.Fprintf(os.Stderr, "Aborted: %v: %v\n", t.Host, core.ErrUnknownHost)
+			os.Exit(2)
+		}
+		targets[t] = ip
+	}
+
+	var ifacetarget = net.IPv4zero
+	if allinterfaces, err := core.ScanInterfaces(); err == nil {
+		if ip, ok := allinterfaces[arg.Interface]; ok {
+			ifacetarget = ip
+		}
+	}
+	pingers := make(map[string]*core.Pinger)
+	protocols := []string{"icmp"}
+	for _, m := range arg.Modules {
+		protocols = append(protocols, m.Protocol)
+	}
+	for _, t := range arg.Probes {
+		protocols = append(protocols, t.Settings.Protocol)
+	}
+	for _, protocol := range protocols {
+		if pingers[protocol] != nil {
+			continue
+		}
+		var pinger *core.Pinger
+		if protocol == "icmp6" {
+			// -I names an IPv4 address; ICMPv6 listens on every interface
+			pinger, err = core.NewPinger6("::", payload)
+		} else {
+			pinger, err = core.NewPinger(ifacetarget.String(), payload)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer pinger.Close()
+		pingers[protocol] = pinger
+	}
+
+	metrics := core.NewMetrics()
+	metrics.SetWindows(core.SystemClock, arg.Windows)
+	for t, ip := range targets {
+		metrics.SetLabels(t.Host, t.Group, t.Labels)
+		go probe(pingers[t.Settings.Protocol], metrics, t, ip)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", exposition)
+		metrics.Render(w)
+	})
+	mux.HandleFunc("/probe", func(w http.ResponseWriter, r *http.Request) {
+		target, module, err := core.ParseProbeQuery(r.URL.Query(), arg.Modules)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", exposition)
+		core.RunProbe(pingers[module.Protocol], target, module).Render(w)
+	})
+	log.Printf("serving /metrics & /probe on %v for %d targets", arg.Listen, len(targets))
+	log.Fatal(http.ListenAndServe(arg.Listen, mux))
+}
+
+// probe pings one target count times every interval, forever.
+func probe(pinger *core.Pinger, metrics *core.Metrics, t *core.ConfigTarget, ip *net.IPAddr) {
+	payload := core.NewPayload(t.Settings.Size)
+	for {
+		start := time.Now()
+		for i := uint64(0); i < t.Settings.Count; i++ {
+			metrics.Observe(t.Host, pinger.PingWith(ip, payload, t.Settings.Timeout))
+		}
+		time.Sleep(t.Settings.Interval - time.Since(start))
+	}
+}